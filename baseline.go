@@ -0,0 +1,59 @@
+package gomimi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var ErrSchemaMismatch = errors.New("schema does not match the migrations")
+
+// SchemaVerifier checks that the database has the schema the migrations would create,
+// it returns an error wrapping ErrSchemaMismatch when it does not
+type SchemaVerifier func(ctx context.Context, db *sql.DB, schema *Schema) error
+
+// WithSchemaVerifier replaces the check Baseline runs before recording migrations as applied,
+// a nil verifier records them without any check
+func WithSchemaVerifier(verifier SchemaVerifier) RunnerOption {
+	return func(runner *Runner) {
+		runner.schemaVerifier = verifier
+	}
+}
+
+func (runner Runner) Baseline(db *sql.DB, name string, migrations ...Migration) {
+	runner.BaselineContext(context.Background(), db, name, migrations...)
+}
+
+// BaselineContext adopts a database whose schema already exists,
+// the given migration and every migration before it are recorded as applied without running them
+// once the schema they create is verified to exist
+func (runner Runner) BaselineContext(ctx context.Context, db *sql.DB, name string, migrations ...Migration) {
+	index := migrationIndex(name, migrations)
+	if index < 0 {
+		panic(fmt.Errorf(`migration with name "%v" not found`, name))
+	}
+
+	if currentMigrationName := runner.indicator.Current(); currentMigrationName != "" {
+		panic(fmt.Errorf(`migration "%v" is already applied, only a database without migrations can be baselined`, currentMigrationName))
+	}
+
+	if runner.schemaVerifier != nil {
+		schema, err := SchemaOf(migrations[:index+1]...)
+		if err != nil {
+			panic(err)
+		}
+		if err := runner.schemaVerifier(ctx, db, schema); err != nil {
+			panic(err)
+		}
+	}
+
+	if history, ok := runner.indicator.(HistoryIndicator); ok {
+		for _, migration := range migrations[:index] {
+			history.MarkApplied(migration.Name())
+		}
+	}
+	runner.markCurrent(name)
+
+	runner.log().Info("database baselined", "migration", name, "migrations", index+1)
+}
@@ -0,0 +1,64 @@
+package gomimi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// VerifySchemaPostgreSQL checks that every table and column of the schema exists in the database,
+// tables and columns that only exist in the database are ignored
+func VerifySchemaPostgreSQL(ctx context.Context, db *sql.DB, schema *Schema) error {
+	var currentSchemaName string
+	if err := db.QueryRowContext(ctx, `SELECT current_schema();`).Scan(&currentSchemaName); err != nil {
+		return err
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT "table_schema", "table_name", "column_name" FROM "information_schema"."columns";`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	existingColumns := map[string]map[string]bool{}
+	for rows.Next() {
+		var schemaName, tableName, columnName string
+		if err := rows.Scan(&schemaName, &tableName, &columnName); err != nil {
+			return err
+		}
+		tableKey := schemaName + "." + tableName
+		if existingColumns[tableKey] == nil {
+			existingColumns[tableKey] = map[string]bool{}
+		}
+		existingColumns[tableKey][columnName] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	mismatches := []string{}
+	for _, table := range schema.Tables {
+		schemaName := table.SchemaName
+		if schemaName == "" {
+			schemaName = currentSchemaName
+		}
+		tableKey := schemaName + "." + table.Name
+
+		columns, ok := existingColumns[tableKey]
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf(`table "%v" does not exist`, tableKey))
+			continue
+		}
+		for _, column := range schema.ColumnsOf(table) {
+			if !columns[column.Name] {
+				mismatches = append(mismatches, fmt.Sprintf(`column "%v" of table "%v" does not exist`, column.Name, tableKey))
+			}
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%w:\n%v", ErrSchemaMismatch, strings.Join(mismatches, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package gomimi
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// informationSchemaRows answers the queries of VerifySchemaPostgreSQL
+// with the given "table.column" names in the public schema
+func informationSchemaRows(columns ...string) func(query string) *fakeRows {
+	return func(query string) *fakeRows {
+		if strings.Contains(query, "current_schema()") {
+			return &fakeRows{columns: []string{"current_schema"}, values: [][]driver.Value{{"public"}}}
+		}
+		rows := &fakeRows{columns: []string{"table_schema", "table_name", "column_name"}}
+		for _, column := range columns {
+			tableName, columnName, _ := strings.Cut(column, ".")
+			rows.values = append(rows.values, []driver.Value{"public", tableName, columnName})
+		}
+		return rows
+	}
+}
+
+func baselineMigrations(runner Runner, db *sql.DB, name string, migrations ...Migration) (err error) {
+	defer recoverTestError(&err)
+	runner.Baseline(db, name, migrations...)
+	return nil
+}
+
+func TestBaseline(t *testing.T) {
+	migrations := []Migration{
+		createTableMigration("001", "users"),
+		createTableMigration("002", "posts"),
+		createTableMigration("003", "comments"),
+	}
+
+	tests := []struct {
+		name        string
+		columns     []string
+		options     []RunnerOption
+		wantErr     error
+		wantCurrent string
+		wantHistory []string
+	}{
+		{
+			name:        "matching schema",
+			columns:     []string{"users.id", "posts.id", "accounts.id"},
+			wantCurrent: "002",
+			wantHistory: []string{"001", "002"},
+		},
+		{
+			name:    "missing table",
+			columns: []string{"users.id"},
+			wantErr: ErrSchemaMismatch,
+		},
+		{
+			name:    "missing column",
+			columns: []string{"users.email", "posts.id"},
+			wantErr: ErrSchemaMismatch,
+		},
+		{
+			name:        "verification disabled",
+			columns:     nil,
+			options:     []RunnerOption{WithSchemaVerifier(nil)},
+			wantCurrent: "002",
+			wantHistory: []string{"001", "002"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := sql.OpenDB(&fakeConnector{rows: informationSchemaRows(test.columns...)})
+			defer db.Close()
+			indicator := &memoryIndicator{}
+			runner := NewRunner(indicator, NewBuilderPostgreSQL(), test.options...)
+
+			err := baselineMigrations(runner, db, "002", migrations...)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Baseline() error = %v, want %v", err, test.wantErr)
+			}
+
+			if indicator.current != test.wantCurrent {
+				t.Errorf("current = %q, want %q", indicator.current, test.wantCurrent)
+			}
+			history := []string{}
+			for _, appliedMigration := range indicator.history {
+				history = append(history, appliedMigration.Name)
+			}
+			if test.wantHistory == nil {
+				test.wantHistory = []string{}
+			}
+			if !reflect.DeepEqual(history, test.wantHistory) {
+				t.Errorf("history = %v, want %v", history, test.wantHistory)
+			}
+		})
+	}
+}
+
+func TestBaselineAppliedDatabase(t *testing.T) {
+	db := sql.OpenDB(&fakeConnector{})
+	defer db.Close()
+	indicator := &memoryIndicator{current: "001", history: []AppliedMigration{{Name: "001"}}}
+	runner := NewRunner(indicator, NewBuilderPostgreSQL(), WithSchemaVerifier(nil))
+
+	err := baselineMigrations(runner, db, "002", createTableMigration("001", "users"), createTableMigration("002", "posts"))
+	if err == nil || !strings.Contains(err.Error(), "already applied") {
+		t.Errorf("Baseline() error = %v, want the applied database refused", err)
+	}
+	if indicator.current != "001" {
+		t.Errorf("current = %q, want %q", indicator.current, "001")
+	}
+}
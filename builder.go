@@ -1,17 +1,53 @@
 package gomimi
 
+import (
+	"time"
+
+	"github.com/ItsMalma/gomimi/types"
+)
+
+type Statement struct {
+	Query     string
+	Arguments []any
+	Batch     *BatchStatement
+}
+
+type BatchStatement struct {
+	NextQuery string
+	Pause     time.Duration
+}
+
+type ColumnStorage uint8
+
+const (
+	ColumnStorageDefault ColumnStorage = iota
+	ColumnStoragePlain
+	ColumnStorageExternal
+	ColumnStorageExtended
+	ColumnStorageMain
+)
+
 type ColumnDefinition struct {
 	Name                 string
-	Type                 string
+	Type                 types.Type
 	Default              string
 	Nullable             bool
 	PrimaryKey           bool
 	Unique               bool
 	Reference            bool
+	ReferenceSchemaName  string
 	ReferenceTableName   string
 	ReferenceColumnNames []string
 	CheckExpression      string
 	AutoIncrement        bool
+	IdentityByDefault    bool
+	IdentityStart        int64
+	IdentityIncrement    int64
+	Comment              string
+	GeneratedExpression  string
+	Collation            string
+	Storage              ColumnStorage
+	Compression          string
 }
 
 type ConstraintDefinitionType uint8
@@ -21,33 +57,366 @@ const (
 	ConstraintUnique
 	ConstraintForeignKey
 	ConstraintCheck
+	ConstraintExclusion
 )
 
+type ExclusionElementDefinition struct {
+	ColumnName string
+	Expression string
+	Operator   string
+}
+
 type ConstraintDefinition struct {
 	Name                 string
 	ColumnNames          []string
 	Type                 ConstraintDefinitionType
 	DefaultExpression    string
+	ReferenceSchemaName  string
 	ReferenceTableName   string
 	ReferenceColumnNames []string
 	CheckExpression      string
+	ExclusionMethod      IndexMethod
+	ExclusionElements    []ExclusionElementDefinition
+	ExclusionWhere       string
+	NotValid             bool
+}
+
+type IndexMethod uint8
+
+const (
+	IndexBTree IndexMethod = iota
+	IndexHash
+	IndexGiST
+	IndexGIN
+	IndexBRIN
+)
+
+type IndexNullsOrder uint8
+
+const (
+	IndexNullsDefault IndexNullsOrder = iota
+	IndexNullsFirst
+	IndexNullsLast
+)
+
+type IndexColumnDefinition struct {
+	Name          string
+	Expression    string
+	OperatorClass string
+	Descending    bool
+	Nulls         IndexNullsOrder
 }
 
 type IndexDefinition struct {
-	Name         string
-	ColumnNames  []string
-	Unique       bool
-	OnExpression string
+	Name               string
+	ColumnNames        []string
+	Columns            []IndexColumnDefinition
+	Method             IndexMethod
+	Unique             bool
+	IncludeColumnNames []string
+	StorageParameters  map[string]string
+	Concurrently       bool
+	OnExpression       string
+}
+
+type SequenceDefinition struct {
+	Name              string
+	Type              types.Type
+	Start             int64
+	Increment         int64
+	MinValue          int64
+	MaxValue          int64
+	Cache             int64
+	Cycle             bool
+	OwnedByTableName  string
+	OwnedByColumnName string
+}
+
+type FunctionVolatility uint8
+
+const (
+	FunctionVolatile FunctionVolatility = iota
+	FunctionStable
+	FunctionImmutable
+)
+
+type FunctionArgumentDefinition struct {
+	Name    string
+	Type    types.Type
+	Default string
+}
+
+type FunctionDefinition struct {
+	Name            string
+	Arguments       []FunctionArgumentDefinition
+	Returns         types.Type
+	Language        string
+	Body            string
+	Volatility      FunctionVolatility
+	SecurityDefiner bool
+}
+
+type TriggerTiming uint8
+
+const (
+	TriggerBefore TriggerTiming = iota
+	TriggerAfter
+	TriggerInsteadOf
+)
+
+type TriggerEvent uint8
+
+const (
+	TriggerInsert TriggerEvent = iota
+	TriggerUpdate
+	TriggerDelete
+	TriggerTruncate
+)
+
+type TriggerDefinition struct {
+	Name               string
+	Timing             TriggerTiming
+	Events             []TriggerEvent
+	UpdateColumnNames  []string
+	ForEachRow         bool
+	WhenCondition      string
+	FunctionSchemaName string
+	FunctionName       string
+	FunctionArguments  []string
+}
+
+type PartitionStrategy uint8
+
+const (
+	PartitionNone PartitionStrategy = iota
+	PartitionRange
+	PartitionList
+	PartitionHash
+)
+
+type PartitionBounds struct {
+	From      []string
+	To        []string
+	In        []string
+	Modulus   int
+	Remainder int
+	Default   bool
+}
+
+type TableOptions struct {
+	Temporary            bool
+	Unlogged             bool
+	InheritsTableNames   []string
+	PartitionStrategy    PartitionStrategy
+	PartitionColumnNames []string
+	StorageParameters    map[string]string
+	Tablespace           string
+}
+
+type TableOption func(options *TableOptions)
+
+func PartitionBy(strategy PartitionStrategy, columnNames ...string) TableOption {
+	return func(options *TableOptions) {
+		options.PartitionStrategy = strategy
+		options.PartitionColumnNames = columnNames
+	}
+}
+
+func Temporary() TableOption {
+	return func(options *TableOptions) {
+		options.Temporary = true
+	}
+}
+
+func Unlogged() TableOption {
+	return func(options *TableOptions) {
+		options.Unlogged = true
+	}
+}
+
+func Inherits(tableNames ...string) TableOption {
+	return func(options *TableOptions) {
+		options.InheritsTableNames = append(options.InheritsTableNames, tableNames...)
+	}
+}
+
+func WithStorageParameter(name string, value string) TableOption {
+	return func(options *TableOptions) {
+		if options.StorageParameters == nil {
+			options.StorageParameters = map[string]string{}
+		}
+		options.StorageParameters[name] = value
+	}
+}
+
+func InTablespace(name string) TableOption {
+	return func(options *TableOptions) {
+		options.Tablespace = name
+	}
+}
+
+type ExtensionDefinition struct {
+	Name       string
+	Version    string
+	SchemaName string
+	Cascade    bool
+}
+
+type GrantObjectType uint8
+
+const (
+	GrantTable GrantObjectType = iota
+	GrantSequence
+	GrantSchema
+	GrantFunction
+)
+
+type GrantDefinition struct {
+	Privileges      []string
+	ObjectType      GrantObjectType
+	ObjectNames     []string
+	RoleNames       []string
+	WithGrantOption bool
+}
+
+type RoleDefinition struct {
+	Name            string
+	Login           bool
+	Password        string
+	Superuser       bool
+	CreateDatabase  bool
+	CreateRole      bool
+	BypassRLS       bool
+	ConnectionLimit int
+	ValidUntil      string
+	InRoleNames     []string
+}
+
+type RoleAttribute uint8
+
+const (
+	RoleAttributeUnchanged RoleAttribute = iota
+	RoleAttributeEnabled
+	RoleAttributeDisabled
+)
+
+// RoleAlteration only changes the attributes that are set,
+// the rest of the role is left as it is
+type RoleAlteration struct {
+	Name            string
+	Login           RoleAttribute
+	Password        string
+	Superuser       RoleAttribute
+	CreateDatabase  RoleAttribute
+	CreateRole      RoleAttribute
+	BypassRLS       RoleAttribute
+	ConnectionLimit int
+	ValidUntil      string
+	InRoleNames     []string
+}
+
+type PolicyCommand uint8
+
+const (
+	PolicyAll PolicyCommand = iota
+	PolicySelect
+	PolicyInsert
+	PolicyUpdate
+	PolicyDelete
+)
+
+type PolicyDefinition struct {
+	Name                string
+	Restrictive         bool
+	Command             PolicyCommand
+	RoleNames           []string
+	UsingExpression     string
+	WithCheckExpression string
+}
+
+type Row map[string]any
+
+type BackfillDefinition struct {
+	TableName            string
+	PrimaryKeyColumnName string
+	Set                  map[string]string
+	Where                string
+	BatchSize            int
+	Pause                time.Duration
+}
+
+type ViewCheckOption uint8
+
+const (
+	ViewCheckNone ViewCheckOption = iota
+	ViewCheckLocal
+	ViewCheckCascaded
+)
+
+type ViewDefinition struct {
+	Name        string
+	ColumnNames []string
+	Query       string
+	CheckOption ViewCheckOption
+}
+
+type MaterializedViewDefinition struct {
+	Name        string
+	ColumnNames []string
+	Query       string
+	WithNoData  bool
+}
+
+type Timeouts struct {
+	LockTimeout      time.Duration
+	StatementTimeout time.Duration
 }
 
 type Builder interface {
 	Begin()
 	Rollback()
 	Commit() string
-	CreateTable(name string, columns []ColumnDefinition, constraints []ConstraintDefinition) TableBuilder
+	Statements() []Statement
+	Deferred() []Statement
+	SetTimeouts(timeouts Timeouts, local bool) Builder
+	InSchema(name string) Builder
+	CreateSchema(name string) Builder
+	DropSchema(name string, cascade bool) Builder
+	CreateExtension(extension ExtensionDefinition) Builder
+	DropExtension(name string, cascade bool) Builder
+	CreateTable(name string, columns []ColumnDefinition, constraints []ConstraintDefinition, options ...TableOption) TableBuilder
+	CreatePartitionOf(name string, parentTableName string, bounds PartitionBounds) TableBuilder
 	AlterTable(name string) TableBuilder
 	DropTable(name string) Builder
 	TruncateTable(name string) Builder
+	CreateView(view ViewDefinition) Builder
+	CreateOrReplaceView(view ViewDefinition) Builder
+	DropView(name string) Builder
+	CreateMaterializedView(view MaterializedViewDefinition) Builder
+	RefreshMaterializedView(name string, concurrently bool) Builder
+	DropMaterializedView(name string) Builder
+	CreateEnum(name string, values ...string) Builder
+	AlterEnum(name string) EnumBuilder
+	DropEnum(name string) Builder
+	CreateSequence(sequence SequenceDefinition) Builder
+	AlterSequence(name string) SequenceBuilder
+	RestartSequence(name string, value int64) Builder
+	DropSequence(name string) Builder
+	CreateFunction(function FunctionDefinition) Builder
+	CreateOrReplaceFunction(function FunctionDefinition) Builder
+	DropFunction(name string, argumentTypes ...types.Type) Builder
+	CreateProcedure(procedure FunctionDefinition) Builder
+	CreateOrReplaceProcedure(procedure FunctionDefinition) Builder
+	DropProcedure(name string, argumentTypes ...types.Type) Builder
+	Grant(grant GrantDefinition) Builder
+	Revoke(grant GrantDefinition) Builder
+	CreateRole(role RoleDefinition) Builder
+	AlterRole(role RoleAlteration) Builder
+	DropRole(name string) Builder
+	Insert(tableName string, rows []Row) Builder
+	Update(tableName string, set Row, where string, arguments ...any) Builder
+	Delete(tableName string, where string, arguments ...any) Builder
+	BatchBackfill(backfill BackfillDefinition) Builder
 }
 
 type TableBuilder interface {
@@ -62,11 +431,44 @@ type TableBuilder interface {
 	RenameColumn(oldColumnName string, newColumnName string) TableBuilder
 	RenameConstraint(oldConstraintName string, newConstraintName string) TableBuilder
 	RenameIndex(oldIndexName string, newIndexName string) TableBuilder
+	ValidateConstraint(constraintName string) TableBuilder
+	AttachPartition(partitionName string, bounds PartitionBounds) TableBuilder
+	DetachPartition(partitionName string, concurrently bool) TableBuilder
+	SetComment(comment string) TableBuilder
+	SetColumnComment(columnName string, comment string) TableBuilder
+	CreateTrigger(trigger TriggerDefinition) TableBuilder
+	DropTrigger(triggerName string) TableBuilder
+	EnableRowLevelSecurity() TableBuilder
+	DisableRowLevelSecurity() TableBuilder
+	ForceRowLevelSecurity() TableBuilder
+	NoForceRowLevelSecurity() TableBuilder
+	CreatePolicy(policy PolicyDefinition) TableBuilder
+	DropPolicy(policyName string) TableBuilder
+}
+
+type EnumBuilder interface {
+	AddValue(value string) EnumBuilder
+	AddValueBefore(value string, beforeValue string) EnumBuilder
+	AddValueAfter(value string, afterValue string) EnumBuilder
+	RenameValue(oldValue string, newValue string) EnumBuilder
+}
+
+type SequenceBuilder interface {
+	SetType(sequenceType types.Type) SequenceBuilder
+	SetIncrement(increment int64) SequenceBuilder
+	SetMinValue(minValue int64) SequenceBuilder
+	SetMaxValue(maxValue int64) SequenceBuilder
+	SetStart(start int64) SequenceBuilder
+	SetCache(cache int64) SequenceBuilder
+	SetCycle(enableCycle bool) SequenceBuilder
+	SetOwnedBy(tableName string, columnName string) SequenceBuilder
+	DropOwnedBy() SequenceBuilder
+	Rename(newSequenceName string) SequenceBuilder
 }
 
 type ColumnBuilder interface {
 	WithName(name string) ColumnBuilder
-	WithType(typeName string) ColumnBuilder
+	WithType(columnType types.Type) ColumnBuilder
 	WithDefault(expression string) ColumnBuilder
 	IsNullable(enableNullable bool) ColumnBuilder
 	IsPrimaryKey(enablePrimaryKey bool) ColumnBuilder
@@ -74,17 +476,28 @@ type ColumnBuilder interface {
 	IsForeignKey(enableForeign bool, referenceTableName string, referenceColumnNames ...string) ColumnBuilder
 	IsCheck(expression string) ColumnBuilder
 	IsAutoIncrement(enableAutoIncrement bool) ColumnBuilder
+	WithIdentity(byDefault bool, start int64, increment int64) ColumnBuilder
+	WithComment(comment string) ColumnBuilder
+	WithGeneratedExpression(expression string) ColumnBuilder
+	WithCollation(collation string) ColumnBuilder
+	WithStorage(storage ColumnStorage) ColumnBuilder
+	WithCompression(method string) ColumnBuilder
 	Build() ColumnDefinition
 }
 
 type AlterColumnBuilder interface {
-	AlterType(typeName string) AlterColumnBuilder
+	AlterType(columnType types.Type) AlterColumnBuilder
 	AlterDefault(expression string) AlterColumnBuilder
 	DropDefault() AlterColumnBuilder
 	SetNullable() AlterColumnBuilder
 	DropNullable() AlterColumnBuilder
 	SetAutoIncrement() AlterColumnBuilder
 	DropAutoIncrement() AlterColumnBuilder
+	SetCollation(columnType types.Type, collation string) AlterColumnBuilder
+	DropExpression() AlterColumnBuilder
+	SetStorage(storage ColumnStorage) AlterColumnBuilder
+	SetCompression(method string) AlterColumnBuilder
+	SetStatistics(target int) AlterColumnBuilder
 }
 
 type ConstraintBuilder interface {
@@ -94,13 +507,21 @@ type ConstraintBuilder interface {
 	IsUnique(enableUnique bool) ConstraintBuilder
 	IsForeignKey(enableForeign bool, referenceTableName string, referenceColumnNames ...string) ConstraintBuilder
 	IsCheck(expression string) ConstraintBuilder
+	IsExclusion(method IndexMethod, elements ...ExclusionElementDefinition) ConstraintBuilder
+	IsNotValid(enableNotValid bool) ConstraintBuilder
 	Build() ConstraintDefinition
 }
 
 type IndexBuilder interface {
 	WithName(name string) IndexBuilder
 	WithColumns(columnNames ...string) IndexBuilder
+	WithColumnDefinitions(columns ...IndexColumnDefinition) IndexBuilder
+	WithExpression(expression string) IndexBuilder
+	Using(method IndexMethod) IndexBuilder
 	IsUnique(enableUnique bool) IndexBuilder
+	Include(columnNames ...string) IndexBuilder
+	WithStorageParameter(name string, value string) IndexBuilder
+	IsConcurrently(enableConcurrently bool) IndexBuilder
 	On(partialCondition string) IndexBuilder
 	Build() IndexDefinition
 }
@@ -2,13 +2,80 @@ package gomimi
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/ItsMalma/gomimi/types"
 )
 
-func writeColumnPostgreSQL(column ColumnDefinition) string {
+func writeTypePostgreSQL(columnType types.Type) string {
+	switch columnType.Kind {
+	case types.KindSmallInt:
+		return `SMALLINT`
+	case types.KindInteger:
+		return `INTEGER`
+	case types.KindBigInt:
+		return `BIGINT`
+	case types.KindReal:
+		return `REAL`
+	case types.KindDouble:
+		return `DOUBLE PRECISION`
+	case types.KindDecimal:
+		if columnType.Precision > 0 {
+			return fmt.Sprintf(`NUMERIC(%v,%v)`, columnType.Precision, columnType.Scale)
+		}
+		return `NUMERIC`
+	case types.KindBoolean:
+		return `BOOLEAN`
+	case types.KindChar:
+		if columnType.Length > 0 {
+			return fmt.Sprintf(`CHAR(%v)`, columnType.Length)
+		}
+		return `CHAR`
+	case types.KindVarchar:
+		if columnType.Length > 0 {
+			return fmt.Sprintf(`VARCHAR(%v)`, columnType.Length)
+		}
+		return `VARCHAR`
+	case types.KindText:
+		return `TEXT`
+	case types.KindBinary:
+		return `BYTEA`
+	case types.KindDate:
+		return `DATE`
+	case types.KindTime:
+		if columnType.WithTimeZone {
+			return `TIMETZ`
+		}
+		return `TIME`
+	case types.KindTimestamp:
+		if columnType.WithTimeZone {
+			return `TIMESTAMPTZ`
+		}
+		return `TIMESTAMP`
+	case types.KindInterval:
+		return `INTERVAL`
+	case types.KindJSON:
+		return `JSONB`
+	case types.KindUUID:
+		return `UUID`
+	case types.KindArray:
+		if columnType.Element == nil {
+			return `TEXT[]`
+		}
+		return fmt.Sprintf(`%v[]`, writeTypePostgreSQL(*columnType.Element))
+	default:
+		return columnType.Name
+	}
+}
+
+func writeColumnPostgreSQL(schemaName string, column ColumnDefinition) string {
 	queryBuilder := new(strings.Builder)
 
-	queryBuilder.WriteString(fmt.Sprintf(`"%v" %v`, column.Name, column.Type))
+	queryBuilder.WriteString(fmt.Sprintf(`"%v" %v`, column.Name, writeTypePostgreSQL(column.Type)))
+	if column.Collation != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` COLLATE "%v"`, column.Collation))
+	}
 	if column.Default != "" {
 		queryBuilder.WriteString(fmt.Sprintf(` DEFAULT %v`, column.Default))
 	}
@@ -24,27 +91,57 @@ func writeColumnPostgreSQL(column ColumnDefinition) string {
 		queryBuilder.WriteString(fmt.Sprintf(` UNIQUE`))
 	}
 	if column.Reference {
-		queryBuilder.WriteString(fmt.Sprintf(` REFERENCES "%v" (`, column.ReferenceTableName))
-		referenceColumnNamesLength := len(column.ReferenceColumnNames)
-		for index2, referenceColumnName := range column.ReferenceColumnNames {
-			queryBuilder.WriteString(fmt.Sprintf(`"%v"`, referenceColumnName))
-			if index2+1 < referenceColumnNamesLength {
-				queryBuilder.WriteString(`,`)
-			}
-			queryBuilder.WriteString(`)`)
-		}
+		queryBuilder.WriteString(
+			fmt.Sprintf(
+				` REFERENCES %v (%v)`,
+				writeReferenceNamePostgreSQL(schemaName, column.ReferenceSchemaName, column.ReferenceTableName),
+				writeNamesPostgreSQL(column.ReferenceColumnNames),
+			),
+		)
 	}
 	if column.CheckExpression != "" {
 		queryBuilder.WriteString(fmt.Sprintf(` CHECK (%v)`, column.CheckExpression))
 	}
 	if column.AutoIncrement {
-		queryBuilder.WriteString(fmt.Sprintf(` GENERATED ALWAYS AS IDENTITY`))
+		if column.IdentityByDefault {
+			queryBuilder.WriteString(` GENERATED BY DEFAULT AS IDENTITY`)
+		} else {
+			queryBuilder.WriteString(` GENERATED ALWAYS AS IDENTITY`)
+		}
+		identityOptions := make([]string, 0, 2)
+		if column.IdentityStart != 0 {
+			identityOptions = append(identityOptions, fmt.Sprintf(`START WITH %v`, column.IdentityStart))
+		}
+		if column.IdentityIncrement != 0 {
+			identityOptions = append(identityOptions, fmt.Sprintf(`INCREMENT BY %v`, column.IdentityIncrement))
+		}
+		if len(identityOptions) > 0 {
+			queryBuilder.WriteString(fmt.Sprintf(` (%v)`, strings.Join(identityOptions, ` `)))
+		}
+	}
+	if column.GeneratedExpression != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` GENERATED ALWAYS AS (%v) STORED`, column.GeneratedExpression))
 	}
 
 	return queryBuilder.String()
 }
 
-func writeConstraintPostgreSQL(constraint ConstraintDefinition) string {
+func writeColumnStoragePostgreSQL(storage ColumnStorage) string {
+	switch storage {
+	case ColumnStoragePlain:
+		return `PLAIN`
+	case ColumnStorageExternal:
+		return `EXTERNAL`
+	case ColumnStorageExtended:
+		return `EXTENDED`
+	case ColumnStorageMain:
+		return `MAIN`
+	default:
+		return ``
+	}
+}
+
+func writeConstraintPostgreSQL(schemaName string, constraint ConstraintDefinition) string {
 	queryBuilder := new(strings.Builder)
 
 	if constraint.Name != "" {
@@ -81,112 +178,1013 @@ func writeConstraintPostgreSQL(constraint ConstraintDefinition) string {
 			}
 		}
 		queryBuilder.WriteString(`)`)
-		queryBuilder.WriteString(fmt.Sprintf(` REFERENCES "%v" (`, constraint.ReferenceTableName))
-		referenceColumnNamesLength := len(constraint.ReferenceColumnNames)
-		for index2, referenceColumnName := range constraint.ReferenceColumnNames {
-			queryBuilder.WriteString(fmt.Sprintf(`"%v"`, referenceColumnName))
-			if index2+1 < referenceColumnNamesLength {
-				queryBuilder.WriteString(`,`)
-			}
-			queryBuilder.WriteString(`)`)
-		}
+		queryBuilder.WriteString(
+			fmt.Sprintf(
+				` REFERENCES %v (%v)`,
+				writeReferenceNamePostgreSQL(schemaName, constraint.ReferenceSchemaName, constraint.ReferenceTableName),
+				writeNamesPostgreSQL(constraint.ReferenceColumnNames),
+			),
+		)
 	case ConstraintCheck:
 		queryBuilder.WriteString(fmt.Sprintf(` CHECK (%v)`, constraint.CheckExpression))
+	case ConstraintExclusion:
+		queryBuilder.WriteString(` EXCLUDE`)
+		if method := writeIndexMethodPostgreSQL(constraint.ExclusionMethod); method != "" {
+			queryBuilder.WriteString(fmt.Sprintf(` USING %v`, method))
+		}
+		elements := make([]string, 0, len(constraint.ExclusionElements))
+		for _, element := range constraint.ExclusionElements {
+			if element.Expression != "" {
+				elements = append(elements, fmt.Sprintf(`(%v) WITH %v`, element.Expression, element.Operator))
+			} else {
+				elements = append(elements, fmt.Sprintf(`"%v" WITH %v`, element.ColumnName, element.Operator))
+			}
+		}
+		queryBuilder.WriteString(fmt.Sprintf(` (%v)`, strings.Join(elements, `,`)))
+		if constraint.ExclusionWhere != "" {
+			queryBuilder.WriteString(fmt.Sprintf(` WHERE (%v)`, constraint.ExclusionWhere))
+		}
+	}
+
+	return queryBuilder.String()
+}
+
+func writeNamesPostgreSQL(names []string) string {
+	quotedNames := make([]string, 0, len(names))
+	for _, name := range names {
+		quotedNames = append(quotedNames, fmt.Sprintf(`"%v"`, name))
+	}
+	return strings.Join(quotedNames, `,`)
+}
+
+// writeReferenceNamePostgreSQL qualifies an object referenced by another one,
+// with its own schema when one is given and the schema of the builder otherwise
+func writeReferenceNamePostgreSQL(builderSchemaName string, schemaName string, name string) string {
+	if schemaName == "" {
+		schemaName = builderSchemaName
+	}
+	return writeQualifiedNamePostgreSQL(schemaName, name)
+}
+
+func writeQualifiedNamePostgreSQL(schemaName string, name string) string {
+	if schemaName != "" {
+		return fmt.Sprintf(`"%v"."%v"`, schemaName, name)
+	}
+	return fmt.Sprintf(`"%v"`, name)
+}
+
+func writeLiteralPostgreSQL(value string) string {
+	return fmt.Sprintf(`'%v'`, strings.ReplaceAll(value, `'`, `''`))
+}
+
+func writeSequencePostgreSQL(schemaName string, sequence SequenceDefinition) string {
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(writeQualifiedNamePostgreSQL(schemaName, sequence.Name))
+	if sequenceType := writeTypePostgreSQL(sequence.Type); sequenceType != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` AS %v`, sequenceType))
+	}
+	if sequence.Increment != 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` INCREMENT BY %v`, sequence.Increment))
+	}
+	if sequence.MinValue != 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` MINVALUE %v`, sequence.MinValue))
+	}
+	if sequence.MaxValue != 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` MAXVALUE %v`, sequence.MaxValue))
+	}
+	if sequence.Start != 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` START WITH %v`, sequence.Start))
+	}
+	if sequence.Cache != 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` CACHE %v`, sequence.Cache))
+	}
+	if sequence.Cycle {
+		queryBuilder.WriteString(` CYCLE`)
+	}
+	if sequence.OwnedByTableName != "" {
+		queryBuilder.WriteString(
+			fmt.Sprintf(
+				` OWNED BY %v."%v"`,
+				writeQualifiedNamePostgreSQL(schemaName, sequence.OwnedByTableName),
+				sequence.OwnedByColumnName,
+			),
+		)
+	}
+
+	return queryBuilder.String()
+}
+
+func writeRoutinePostgreSQL(schemaName string, function FunctionDefinition, procedure bool) string {
+	queryBuilder := new(strings.Builder)
+
+	arguments := make([]string, 0, len(function.Arguments))
+	for _, argument := range function.Arguments {
+		argumentBuilder := new(strings.Builder)
+		if argument.Name != "" {
+			argumentBuilder.WriteString(fmt.Sprintf(`"%v" `, argument.Name))
+		}
+		argumentBuilder.WriteString(writeTypePostgreSQL(argument.Type))
+		if argument.Default != "" {
+			argumentBuilder.WriteString(fmt.Sprintf(` DEFAULT %v`, argument.Default))
+		}
+		arguments = append(arguments, argumentBuilder.String())
+	}
+	queryBuilder.WriteString(
+		fmt.Sprintf(
+			`%v(%v)`,
+			writeQualifiedNamePostgreSQL(schemaName, function.Name),
+			strings.Join(arguments, `,`),
+		),
+	)
+
+	if !procedure {
+		queryBuilder.WriteString(fmt.Sprintf(` RETURNS %v`, writeTypePostgreSQL(function.Returns)))
+	}
+	if function.Language != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` LANGUAGE %v`, function.Language))
+	}
+	if !procedure {
+		switch function.Volatility {
+		case FunctionStable:
+			queryBuilder.WriteString(` STABLE`)
+		case FunctionImmutable:
+			queryBuilder.WriteString(` IMMUTABLE`)
+		}
+	}
+	if function.SecurityDefiner {
+		queryBuilder.WriteString(` SECURITY DEFINER`)
+	}
+	queryBuilder.WriteString(fmt.Sprintf(` AS $gomimi$%v$gomimi$`, function.Body))
+
+	return queryBuilder.String()
+}
+
+func writeRoutineSignaturePostgreSQL(schemaName string, name string, argumentTypes []types.Type) string {
+	writtenArgumentTypes := make([]string, 0, len(argumentTypes))
+	for _, argumentType := range argumentTypes {
+		writtenArgumentTypes = append(writtenArgumentTypes, writeTypePostgreSQL(argumentType))
+	}
+	return fmt.Sprintf(`%v(%v)`, writeQualifiedNamePostgreSQL(schemaName, name), strings.Join(writtenArgumentTypes, `,`))
+}
+
+func writePartitionBoundsPostgreSQL(bounds PartitionBounds) string {
+	switch {
+	case bounds.Default:
+		return `DEFAULT`
+	case len(bounds.In) > 0:
+		return fmt.Sprintf(`FOR VALUES IN (%v)`, strings.Join(bounds.In, `,`))
+	case bounds.Modulus > 0:
+		return fmt.Sprintf(`FOR VALUES WITH (MODULUS %v, REMAINDER %v)`, bounds.Modulus, bounds.Remainder)
+	default:
+		return fmt.Sprintf(`FOR VALUES FROM (%v) TO (%v)`, strings.Join(bounds.From, `,`), strings.Join(bounds.To, `,`))
+	}
+}
+
+func writeRoleNamesPostgreSQL(roleNames []string) string {
+	writtenRoleNames := make([]string, 0, len(roleNames))
+	for _, roleName := range roleNames {
+		switch strings.ToUpper(roleName) {
+		case `PUBLIC`, `CURRENT_USER`, `CURRENT_ROLE`, `SESSION_USER`:
+			writtenRoleNames = append(writtenRoleNames, strings.ToUpper(roleName))
+		default:
+			writtenRoleNames = append(writtenRoleNames, fmt.Sprintf(`"%v"`, roleName))
+		}
+	}
+	return strings.Join(writtenRoleNames, `,`)
+}
+
+func writeGrantObjectsPostgreSQL(schemaName string, grant GrantDefinition) string {
+	objectNames := make([]string, 0, len(grant.ObjectNames))
+	for _, objectName := range grant.ObjectNames {
+		if grant.ObjectType == GrantSchema {
+			objectNames = append(objectNames, fmt.Sprintf(`"%v"`, objectName))
+		} else {
+			objectNames = append(objectNames, writeQualifiedNamePostgreSQL(schemaName, objectName))
+		}
+	}
+
+	privileges := `ALL`
+	if len(grant.Privileges) > 0 {
+		privileges = strings.Join(grant.Privileges, `,`)
+	}
+
+	switch grant.ObjectType {
+	case GrantSequence:
+		return fmt.Sprintf(`%v ON SEQUENCE %v`, privileges, strings.Join(objectNames, `,`))
+	case GrantSchema:
+		return fmt.Sprintf(`%v ON SCHEMA %v`, privileges, strings.Join(objectNames, `,`))
+	case GrantFunction:
+		return fmt.Sprintf(`%v ON FUNCTION %v`, privileges, strings.Join(objectNames, `,`))
+	default:
+		return fmt.Sprintf(`%v ON TABLE %v`, privileges, strings.Join(objectNames, `,`))
+	}
+}
+
+func writeRolePostgreSQL(role RoleDefinition) string {
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(fmt.Sprintf(`"%v" WITH`, role.Name))
+	if role.Login {
+		queryBuilder.WriteString(` LOGIN`)
+	} else {
+		queryBuilder.WriteString(` NOLOGIN`)
+	}
+	if role.Superuser {
+		queryBuilder.WriteString(` SUPERUSER`)
+	} else {
+		queryBuilder.WriteString(` NOSUPERUSER`)
+	}
+	if role.CreateDatabase {
+		queryBuilder.WriteString(` CREATEDB`)
+	} else {
+		queryBuilder.WriteString(` NOCREATEDB`)
+	}
+	if role.CreateRole {
+		queryBuilder.WriteString(` CREATEROLE`)
+	} else {
+		queryBuilder.WriteString(` NOCREATEROLE`)
+	}
+	if role.BypassRLS {
+		queryBuilder.WriteString(` BYPASSRLS`)
+	} else {
+		queryBuilder.WriteString(` NOBYPASSRLS`)
+	}
+	if role.ConnectionLimit != 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` CONNECTION LIMIT %v`, role.ConnectionLimit))
+	}
+	if role.Password != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` PASSWORD %v`, writeLiteralPostgreSQL(role.Password)))
+	}
+	if role.ValidUntil != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` VALID UNTIL %v`, writeLiteralPostgreSQL(role.ValidUntil)))
+	}
+
+	return queryBuilder.String()
+}
+
+func writeRoleAttributePostgreSQL(attribute RoleAttribute, name string) string {
+	switch attribute {
+	case RoleAttributeEnabled:
+		return ` ` + name
+	case RoleAttributeDisabled:
+		return ` NO` + name
+	default:
+		return ``
+	}
+}
+
+func writeRoleAlterationPostgreSQL(role RoleAlteration) string {
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(writeRoleAttributePostgreSQL(role.Login, `LOGIN`))
+	queryBuilder.WriteString(writeRoleAttributePostgreSQL(role.Superuser, `SUPERUSER`))
+	queryBuilder.WriteString(writeRoleAttributePostgreSQL(role.CreateDatabase, `CREATEDB`))
+	queryBuilder.WriteString(writeRoleAttributePostgreSQL(role.CreateRole, `CREATEROLE`))
+	queryBuilder.WriteString(writeRoleAttributePostgreSQL(role.BypassRLS, `BYPASSRLS`))
+	if role.ConnectionLimit != 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` CONNECTION LIMIT %v`, role.ConnectionLimit))
+	}
+	if role.Password != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` PASSWORD %v`, writeLiteralPostgreSQL(role.Password)))
+	}
+	if role.ValidUntil != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` VALID UNTIL %v`, writeLiteralPostgreSQL(role.ValidUntil)))
+	}
+
+	return queryBuilder.String()
+}
+
+func writeViewPostgreSQL(schemaName string, view ViewDefinition) string {
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(fmt.Sprintf(`VIEW %v`, writeQualifiedNamePostgreSQL(schemaName, view.Name)))
+	if len(view.ColumnNames) > 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` (%v)`, writeNamesPostgreSQL(view.ColumnNames)))
+	}
+	queryBuilder.WriteString(fmt.Sprintf(` AS %v`, strings.TrimSuffix(strings.TrimSpace(view.Query), `;`)))
+	switch view.CheckOption {
+	case ViewCheckLocal:
+		queryBuilder.WriteString(` WITH LOCAL CHECK OPTION`)
+	case ViewCheckCascaded:
+		queryBuilder.WriteString(` WITH CASCADED CHECK OPTION`)
+	}
+
+	return queryBuilder.String()
+}
+
+func writeIndexMethodPostgreSQL(method IndexMethod) string {
+	switch method {
+	case IndexHash:
+		return `hash`
+	case IndexGiST:
+		return `gist`
+	case IndexGIN:
+		return `gin`
+	case IndexBRIN:
+		return `brin`
+	default:
+		return ``
+	}
+}
+
+func writeIndexColumnPostgreSQL(column IndexColumnDefinition) string {
+	queryBuilder := new(strings.Builder)
+
+	if column.Expression != "" {
+		queryBuilder.WriteString(fmt.Sprintf(`(%v)`, column.Expression))
+	} else {
+		queryBuilder.WriteString(fmt.Sprintf(`"%v"`, column.Name))
+	}
+	if column.OperatorClass != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` %v`, column.OperatorClass))
+	}
+	if column.Descending {
+		queryBuilder.WriteString(` DESC`)
+	}
+	switch column.Nulls {
+	case IndexNullsFirst:
+		queryBuilder.WriteString(` NULLS FIRST`)
+	case IndexNullsLast:
+		queryBuilder.WriteString(` NULLS LAST`)
+	}
+
+	return queryBuilder.String()
+}
+
+func writeStorageParametersPostgreSQL(parameters map[string]string) string {
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	assignments := make([]string, 0, len(names))
+	for _, name := range names {
+		assignments = append(assignments, fmt.Sprintf(`%v=%v`, name, parameters[name]))
+	}
+
+	return strings.Join(assignments, `,`)
+}
+
+type queryPostgreSQL struct {
+	statements         []Statement
+	deferredStatements []Statement
+	timeouts           Timeouts
+	inOrder            bool
+}
+
+func (query *queryPostgreSQL) write(statement string, arguments ...any) {
+	query.statements = append(query.statements, Statement{Query: statement, Arguments: arguments})
+}
+
+func (query *queryPostgreSQL) writeDeferred(statement string) {
+	query.deferStatement(Statement{Query: statement})
+}
+
+func (query *queryPostgreSQL) deferStatement(statement Statement) {
+	if query.inOrder {
+		query.statements = append(query.statements, statement)
+		return
+	}
+	query.deferredStatements = append(query.deferredStatements, statement)
+}
+
+type builderPostgreSQL struct {
+	schemaName string
+	query      *queryPostgreSQL
+}
+
+func NewBuilderPostgreSQL() Builder {
+	return &builderPostgreSQL{query: new(queryPostgreSQL)}
+}
+
+func (builder *builderPostgreSQL) WriteInOrder(enable bool) {
+	builder.query.inOrder = enable
+}
+
+func (builder *builderPostgreSQL) Begin() {
+	builder.query.write(`BEGIN;`)
+}
+
+func (builder *builderPostgreSQL) Rollback() {
+	builder.query.statements = nil
+	builder.query.deferredStatements = nil
+	builder.query.timeouts = Timeouts{}
+}
+
+func (builder *builderPostgreSQL) Commit() string {
+	builder.query.write(`COMMIT;`)
+	queries := make([]string, 0, len(builder.query.statements))
+	for _, statement := range builder.Statements() {
+		queries = append(queries, statement.Query)
+	}
+	return strings.Join(queries, "\n\n")
+}
+
+func (builder *builderPostgreSQL) Statements() []Statement {
+	result := builder.query.statements
+	builder.query.statements = nil
+	return result
+}
+
+func (builder *builderPostgreSQL) Deferred() []Statement {
+	result := builder.query.deferredStatements
+	// deferred statements run outside the transaction,
+	// so the timeouts are set for the session and reset afterwards
+	if len(result) > 0 && builder.query.timeouts != (Timeouts{}) {
+		result = append(writeTimeoutsPostgreSQL(`SET`, builder.query.timeouts), result...)
+		if builder.query.timeouts.LockTimeout > 0 {
+			result = append(result, Statement{Query: `RESET lock_timeout;`})
+		}
+		if builder.query.timeouts.StatementTimeout > 0 {
+			result = append(result, Statement{Query: `RESET statement_timeout;`})
+		}
+	}
+	builder.query.deferredStatements = nil
+	builder.query.timeouts = Timeouts{}
+	return result
+}
+
+func writeTimeoutsPostgreSQL(command string, timeouts Timeouts) []Statement {
+	statements := []Statement{}
+	if timeouts.LockTimeout > 0 {
+		statements = append(statements, Statement{Query: fmt.Sprintf(`%v lock_timeout = '%vms';`, command, timeouts.LockTimeout.Milliseconds())})
+	}
+	if timeouts.StatementTimeout > 0 {
+		statements = append(statements, Statement{Query: fmt.Sprintf(`%v statement_timeout = '%vms';`, command, timeouts.StatementTimeout.Milliseconds())})
+	}
+	return statements
+}
+
+func (builder *builderPostgreSQL) SetTimeouts(timeouts Timeouts, local bool) Builder {
+	if !local {
+		builder.query.statements = append(builder.query.statements, writeTimeoutsPostgreSQL(`SET`, timeouts)...)
+		return builder
+	}
+	builder.query.timeouts = timeouts
+	builder.query.statements = append(builder.query.statements, writeTimeoutsPostgreSQL(`SET LOCAL`, timeouts)...)
+	return builder
+}
+
+func (builder *builderPostgreSQL) InSchema(name string) Builder {
+	return &builderPostgreSQL{schemaName: name, query: builder.query}
+}
+
+func (builder *builderPostgreSQL) CreateSchema(name string) Builder {
+	builder.query.write(fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS "%v";`, name))
+	return builder
+}
+
+func (builder *builderPostgreSQL) DropSchema(name string, cascade bool) Builder {
+	if cascade {
+		builder.query.write(fmt.Sprintf(`DROP SCHEMA IF EXISTS "%v" CASCADE;`, name))
+	} else {
+		builder.query.write(fmt.Sprintf(`DROP SCHEMA IF EXISTS "%v";`, name))
+	}
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateExtension(extension ExtensionDefinition) Builder {
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(fmt.Sprintf(`CREATE EXTENSION IF NOT EXISTS "%v"`, extension.Name))
+	if extension.SchemaName != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` SCHEMA "%v"`, extension.SchemaName))
+	}
+	if extension.Version != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` VERSION %v`, writeLiteralPostgreSQL(extension.Version)))
+	}
+	if extension.Cascade {
+		queryBuilder.WriteString(` CASCADE`)
+	}
+	queryBuilder.WriteString(`;`)
+
+	builder.query.write(queryBuilder.String())
+
+	return builder
+}
+
+func (builder *builderPostgreSQL) DropExtension(name string, cascade bool) Builder {
+	if cascade {
+		builder.query.write(fmt.Sprintf(`DROP EXTENSION IF EXISTS "%v" CASCADE;`, name))
+	} else {
+		builder.query.write(fmt.Sprintf(`DROP EXTENSION IF EXISTS "%v";`, name))
+	}
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateTable(name string, columns []ColumnDefinition, constraints []ConstraintDefinition, options ...TableOption) TableBuilder {
+	tableOptions := TableOptions{}
+	for _, option := range options {
+		option(&tableOptions)
+	}
+
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(`CREATE `)
+	if tableOptions.Temporary {
+		queryBuilder.WriteString(`TEMPORARY `)
+	} else if tableOptions.Unlogged {
+		queryBuilder.WriteString(`UNLOGGED `)
+	}
+	queryBuilder.WriteString(fmt.Sprintf(`TABLE IF NOT EXISTS %v (`, writeQualifiedNamePostgreSQL(builder.schemaName, name)))
+
+	definitions := make([]string, 0, len(columns)+len(constraints))
+	for _, column := range columns {
+		definitions = append(definitions, writeColumnPostgreSQL(builder.schemaName, column))
+	}
+	for _, constraint := range constraints {
+		definitions = append(definitions, writeConstraintPostgreSQL(builder.schemaName, constraint))
+	}
+	queryBuilder.WriteString(strings.Join(definitions, `,`))
+
+	queryBuilder.WriteString(`)`)
+
+	if len(tableOptions.InheritsTableNames) > 0 {
+		inheritsTableNames := make([]string, 0, len(tableOptions.InheritsTableNames))
+		for _, tableName := range tableOptions.InheritsTableNames {
+			inheritsTableNames = append(inheritsTableNames, writeQualifiedNamePostgreSQL(builder.schemaName, tableName))
+		}
+		queryBuilder.WriteString(fmt.Sprintf(` INHERITS (%v)`, strings.Join(inheritsTableNames, `,`)))
+	}
+
+	switch tableOptions.PartitionStrategy {
+	case PartitionRange:
+		queryBuilder.WriteString(fmt.Sprintf(` PARTITION BY RANGE (%v)`, writeNamesPostgreSQL(tableOptions.PartitionColumnNames)))
+	case PartitionList:
+		queryBuilder.WriteString(fmt.Sprintf(` PARTITION BY LIST (%v)`, writeNamesPostgreSQL(tableOptions.PartitionColumnNames)))
+	case PartitionHash:
+		queryBuilder.WriteString(fmt.Sprintf(` PARTITION BY HASH (%v)`, writeNamesPostgreSQL(tableOptions.PartitionColumnNames)))
+	}
+
+	if len(tableOptions.StorageParameters) > 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` WITH (%v)`, writeStorageParametersPostgreSQL(tableOptions.StorageParameters)))
+	}
+
+	if tableOptions.Tablespace != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` TABLESPACE "%v"`, tableOptions.Tablespace))
+	}
+
+	queryBuilder.WriteString(`;`)
+
+	builder.query.write(queryBuilder.String())
+
+	tableBuilder := &tableBuilderPostgreSQL{schemaName: builder.schemaName, tableName: name, query: builder.query}
+	for _, column := range columns {
+		tableBuilder.writeColumnAttributes(column)
+	}
+
+	return tableBuilder
+}
+
+func (builder *builderPostgreSQL) CreatePartitionOf(name string, parentTableName string, bounds PartitionBounds) TableBuilder {
+	builder.query.write(
+		fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %v PARTITION OF %v %v;`,
+			writeQualifiedNamePostgreSQL(builder.schemaName, name),
+			writeQualifiedNamePostgreSQL(builder.schemaName, parentTableName),
+			writePartitionBoundsPostgreSQL(bounds),
+		),
+	)
+
+	return &tableBuilderPostgreSQL{schemaName: builder.schemaName, tableName: name, query: builder.query}
+}
+
+func (builder *builderPostgreSQL) AlterTable(name string) TableBuilder {
+	return &tableBuilderPostgreSQL{schemaName: builder.schemaName, tableName: name, query: builder.query}
+}
+
+func (builder *builderPostgreSQL) DropTable(name string) Builder {
+	builder.query.write(fmt.Sprintf(`DROP TABLE IF EXISTS %v;`, writeQualifiedNamePostgreSQL(builder.schemaName, name)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) TruncateTable(name string) Builder {
+	builder.query.write(fmt.Sprintf(`TRUNCATE TABLE %v;`, writeQualifiedNamePostgreSQL(builder.schemaName, name)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateView(view ViewDefinition) Builder {
+	builder.query.write(fmt.Sprintf(`CREATE %v;`, writeViewPostgreSQL(builder.schemaName, view)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateOrReplaceView(view ViewDefinition) Builder {
+	builder.query.write(fmt.Sprintf(`CREATE OR REPLACE %v;`, writeViewPostgreSQL(builder.schemaName, view)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) DropView(name string) Builder {
+	builder.query.write(fmt.Sprintf(`DROP VIEW IF EXISTS %v;`, writeQualifiedNamePostgreSQL(builder.schemaName, name)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateMaterializedView(view MaterializedViewDefinition) Builder {
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(fmt.Sprintf(`CREATE MATERIALIZED VIEW IF NOT EXISTS %v`, writeQualifiedNamePostgreSQL(builder.schemaName, view.Name)))
+	if len(view.ColumnNames) > 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` (%v)`, writeNamesPostgreSQL(view.ColumnNames)))
+	}
+	queryBuilder.WriteString(fmt.Sprintf(` AS %v`, strings.TrimSuffix(strings.TrimSpace(view.Query), `;`)))
+	if view.WithNoData {
+		queryBuilder.WriteString(` WITH NO DATA`)
+	}
+	queryBuilder.WriteString(`;`)
+
+	builder.query.write(queryBuilder.String())
+
+	return builder
+}
+
+func (builder *builderPostgreSQL) RefreshMaterializedView(name string, concurrently bool) Builder {
+	if concurrently {
+		builder.query.write(fmt.Sprintf(`REFRESH MATERIALIZED VIEW CONCURRENTLY %v;`, writeQualifiedNamePostgreSQL(builder.schemaName, name)))
+	} else {
+		builder.query.write(fmt.Sprintf(`REFRESH MATERIALIZED VIEW %v;`, writeQualifiedNamePostgreSQL(builder.schemaName, name)))
+	}
+	return builder
+}
+
+func (builder *builderPostgreSQL) DropMaterializedView(name string) Builder {
+	builder.query.write(fmt.Sprintf(`DROP MATERIALIZED VIEW IF EXISTS %v;`, writeQualifiedNamePostgreSQL(builder.schemaName, name)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateEnum(name string, values ...string) Builder {
+	literalValues := make([]string, 0, len(values))
+	for _, value := range values {
+		literalValues = append(literalValues, writeLiteralPostgreSQL(value))
+	}
+	builder.query.write(
+		fmt.Sprintf(
+			`CREATE TYPE %v AS ENUM (%v);`,
+			writeQualifiedNamePostgreSQL(builder.schemaName, name),
+			strings.Join(literalValues, `,`),
+		),
+	)
+	return builder
+}
+
+func (builder *builderPostgreSQL) AlterEnum(name string) EnumBuilder {
+	return &enumBuilderPostgreSQL{schemaName: builder.schemaName, enumName: name, query: builder.query}
+}
+
+func (builder *builderPostgreSQL) DropEnum(name string) Builder {
+	builder.query.write(fmt.Sprintf(`DROP TYPE IF EXISTS %v;`, writeQualifiedNamePostgreSQL(builder.schemaName, name)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateSequence(sequence SequenceDefinition) Builder {
+	builder.query.write(fmt.Sprintf(`CREATE SEQUENCE IF NOT EXISTS %v;`, writeSequencePostgreSQL(builder.schemaName, sequence)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) AlterSequence(name string) SequenceBuilder {
+	return &sequenceBuilderPostgreSQL{schemaName: builder.schemaName, sequenceName: name, query: builder.query}
+}
+
+func (builder *builderPostgreSQL) RestartSequence(name string, value int64) Builder {
+	if value != 0 {
+		builder.query.write(
+			fmt.Sprintf(
+				`ALTER SEQUENCE IF EXISTS %v RESTART WITH %v;`,
+				writeQualifiedNamePostgreSQL(builder.schemaName, name),
+				value,
+			),
+		)
+	} else {
+		builder.query.write(fmt.Sprintf(`ALTER SEQUENCE IF EXISTS %v RESTART;`, writeQualifiedNamePostgreSQL(builder.schemaName, name)))
+	}
+	return builder
+}
+
+func (builder *builderPostgreSQL) DropSequence(name string) Builder {
+	builder.query.write(fmt.Sprintf(`DROP SEQUENCE IF EXISTS %v;`, writeQualifiedNamePostgreSQL(builder.schemaName, name)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateFunction(function FunctionDefinition) Builder {
+	builder.query.write(fmt.Sprintf(`CREATE FUNCTION %v;`, writeRoutinePostgreSQL(builder.schemaName, function, false)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateOrReplaceFunction(function FunctionDefinition) Builder {
+	builder.query.write(fmt.Sprintf(`CREATE OR REPLACE FUNCTION %v;`, writeRoutinePostgreSQL(builder.schemaName, function, false)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) DropFunction(name string, argumentTypes ...types.Type) Builder {
+	builder.query.write(fmt.Sprintf(`DROP FUNCTION IF EXISTS %v;`, writeRoutineSignaturePostgreSQL(builder.schemaName, name, argumentTypes)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateProcedure(procedure FunctionDefinition) Builder {
+	builder.query.write(fmt.Sprintf(`CREATE PROCEDURE %v;`, writeRoutinePostgreSQL(builder.schemaName, procedure, true)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateOrReplaceProcedure(procedure FunctionDefinition) Builder {
+	builder.query.write(fmt.Sprintf(`CREATE OR REPLACE PROCEDURE %v;`, writeRoutinePostgreSQL(builder.schemaName, procedure, true)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) DropProcedure(name string, argumentTypes ...types.Type) Builder {
+	builder.query.write(fmt.Sprintf(`DROP PROCEDURE IF EXISTS %v;`, writeRoutineSignaturePostgreSQL(builder.schemaName, name, argumentTypes)))
+	return builder
+}
+
+func (builder *builderPostgreSQL) Grant(grant GrantDefinition) Builder {
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(
+		fmt.Sprintf(
+			`GRANT %v TO %v`,
+			writeGrantObjectsPostgreSQL(builder.schemaName, grant),
+			writeRoleNamesPostgreSQL(grant.RoleNames),
+		),
+	)
+	if grant.WithGrantOption {
+		queryBuilder.WriteString(` WITH GRANT OPTION`)
+	}
+	queryBuilder.WriteString(`;`)
+
+	builder.query.write(queryBuilder.String())
+
+	return builder
+}
+
+func (builder *builderPostgreSQL) Revoke(grant GrantDefinition) Builder {
+	if grant.WithGrantOption {
+		builder.query.write(
+			fmt.Sprintf(
+				`REVOKE GRANT OPTION FOR %v FROM %v;`,
+				writeGrantObjectsPostgreSQL(builder.schemaName, grant),
+				writeRoleNamesPostgreSQL(grant.RoleNames),
+			),
+		)
+	} else {
+		builder.query.write(
+			fmt.Sprintf(
+				`REVOKE %v FROM %v;`,
+				writeGrantObjectsPostgreSQL(builder.schemaName, grant),
+				writeRoleNamesPostgreSQL(grant.RoleNames),
+			),
+		)
+	}
+	return builder
+}
+
+func (builder *builderPostgreSQL) CreateRole(role RoleDefinition) Builder {
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(fmt.Sprintf(`CREATE ROLE %v`, writeRolePostgreSQL(role)))
+	if len(role.InRoleNames) > 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` IN ROLE %v`, writeRoleNamesPostgreSQL(role.InRoleNames)))
+	}
+	queryBuilder.WriteString(`;`)
+
+	builder.query.write(queryBuilder.String())
+
+	return builder
+}
+
+func (builder *builderPostgreSQL) AlterRole(role RoleAlteration) Builder {
+	if attributes := writeRoleAlterationPostgreSQL(role); attributes != "" {
+		builder.query.write(fmt.Sprintf(`ALTER ROLE "%v" WITH%v;`, role.Name, attributes))
+	}
+	for _, inRoleName := range role.InRoleNames {
+		builder.query.write(fmt.Sprintf(`GRANT %v TO "%v";`, writeRoleNamesPostgreSQL([]string{inRoleName}), role.Name))
+	}
+	return builder
+}
+
+func (builder *builderPostgreSQL) DropRole(name string) Builder {
+	builder.query.write(fmt.Sprintf(`DROP ROLE IF EXISTS "%v";`, name))
+	return builder
+}
+
+func (builder *builderPostgreSQL) Insert(tableName string, rows []Row) Builder {
+	if len(rows) == 0 {
+		return builder
+	}
+
+	columnNamesSet := map[string]bool{}
+	for _, row := range rows {
+		for columnName := range row {
+			columnNamesSet[columnName] = true
+		}
+	}
+	columnNames := make([]string, 0, len(columnNamesSet))
+	for columnName := range columnNamesSet {
+		columnNames = append(columnNames, columnName)
+	}
+	sort.Strings(columnNames)
+
+	// an empty column list is not valid, rows without values only use the defaults
+	if len(columnNames) == 0 {
+		for range rows {
+			builder.query.write(fmt.Sprintf(`INSERT INTO %v DEFAULT VALUES;`, writeQualifiedNamePostgreSQL(builder.schemaName, tableName)))
+		}
+		return builder
+	}
+
+	arguments := make([]any, 0, len(rows)*len(columnNames))
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		placeholders := make([]string, 0, len(columnNames))
+		for _, columnName := range columnNames {
+			if value, ok := row[columnName]; ok {
+				arguments = append(arguments, value)
+				placeholders = append(placeholders, fmt.Sprintf(`$%v`, len(arguments)))
+			} else {
+				placeholders = append(placeholders, `DEFAULT`)
+			}
+		}
+		values = append(values, fmt.Sprintf(`(%v)`, strings.Join(placeholders, `,`)))
+	}
+
+	builder.query.write(
+		fmt.Sprintf(
+			`INSERT INTO %v (%v) VALUES %v;`,
+			writeQualifiedNamePostgreSQL(builder.schemaName, tableName),
+			writeNamesPostgreSQL(columnNames),
+			strings.Join(values, `,`),
+		),
+		arguments...,
+	)
+
+	return builder
+}
+
+// placeholders of the where expression are numbered first ($1, $2, ...),
+// the values of set are bound after them
+func (builder *builderPostgreSQL) Update(tableName string, set Row, where string, arguments ...any) Builder {
+	columnNames := make([]string, 0, len(set))
+	for columnName := range set {
+		columnNames = append(columnNames, columnName)
 	}
+	sort.Strings(columnNames)
 
-	return queryBuilder.String()
-}
+	arguments = append(make([]any, 0, len(arguments)+len(columnNames)), arguments...)
+	assignments := make([]string, 0, len(columnNames))
+	for _, columnName := range columnNames {
+		arguments = append(arguments, set[columnName])
+		assignments = append(assignments, fmt.Sprintf(`"%v" = $%v`, columnName, len(arguments)))
+	}
 
-type builderPostgreSQL struct {
-	queryBuilder *strings.Builder
-}
+	queryBuilder := new(strings.Builder)
+	queryBuilder.WriteString(
+		fmt.Sprintf(
+			`UPDATE %v SET %v`,
+			writeQualifiedNamePostgreSQL(builder.schemaName, tableName),
+			strings.Join(assignments, `,`),
+		),
+	)
+	if where != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` WHERE %v`, where))
+	}
+	queryBuilder.WriteString(`;`)
 
-func NewBuilderPostgreSQL() Builder {
-	return &builderPostgreSQL{queryBuilder: new(strings.Builder)}
-}
+	builder.query.write(queryBuilder.String(), arguments...)
 
-func (builder *builderPostgreSQL) Begin() {
-	builder.queryBuilder.WriteString("BEGIN;\n\n")
+	return builder
 }
 
-func (builder *builderPostgreSQL) Rollback() {
-	builder.queryBuilder.WriteString("ROLLBACK;")
-	builder.queryBuilder.Reset()
+func (builder *builderPostgreSQL) Delete(tableName string, where string, arguments ...any) Builder {
+	if where != "" {
+		builder.query.write(
+			fmt.Sprintf(`DELETE FROM %v WHERE %v;`, writeQualifiedNamePostgreSQL(builder.schemaName, tableName), where),
+			arguments...,
+		)
+	} else {
+		builder.query.write(fmt.Sprintf(`DELETE FROM %v;`, writeQualifiedNamePostgreSQL(builder.schemaName, tableName)), arguments...)
+	}
+	return builder
 }
 
-func (builder *builderPostgreSQL) Commit() string {
-	builder.queryBuilder.WriteString("COMMIT;")
-	result := builder.queryBuilder.String()
-	builder.queryBuilder.Reset()
-	return result
-}
+// the backfill runs after the transaction in batches ordered by the primary key,
+// every batch returns its greatest key which is bound as $1 of the next batch
+// until a batch updates no rows
+func (builder *builderPostgreSQL) BatchBackfill(backfill BackfillDefinition) Builder {
+	batchSize := backfill.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
 
-func (builder *builderPostgreSQL) CreateTable(name string, columns []ColumnDefinition, constraints []ConstraintDefinition) TableBuilder {
-	builder.queryBuilder.WriteString(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS "%v" (`, name))
+	columnNames := make([]string, 0, len(backfill.Set))
+	for columnName := range backfill.Set {
+		columnNames = append(columnNames, columnName)
+	}
+	sort.Strings(columnNames)
 
-	columnsLength := len(columns)
-	for index, column := range columns {
-		builder.queryBuilder.WriteString(fmt.Sprintf(`%v`, writeColumnPostgreSQL(column)))
-		if index+1 < columnsLength {
-			builder.queryBuilder.WriteString(`,`)
-		}
+	assignments := make([]string, 0, len(columnNames))
+	for _, columnName := range columnNames {
+		assignments = append(assignments, fmt.Sprintf(`"%v" = %v`, columnName, backfill.Set[columnName]))
 	}
 
-	constraintsLength := len(constraints)
-	for index, constraint := range constraints {
-		builder.queryBuilder.WriteString(fmt.Sprintf(`%v`, writeConstraintPostgreSQL(constraint)))
-		if index+1 < constraintsLength {
-			builder.queryBuilder.WriteString(`,`)
+	writeQuery := func(conditions []string) string {
+		where := ``
+		if len(conditions) > 0 {
+			where = fmt.Sprintf(` WHERE %v`, strings.Join(conditions, ` AND `))
 		}
+		return fmt.Sprintf(
+			`WITH "batch" AS (SELECT "%v" FROM %v%v ORDER BY "%v" LIMIT %v), `+
+				`"updated" AS (UPDATE %v AS "target" SET %v FROM "batch" WHERE "target"."%v" = "batch"."%v" RETURNING "target"."%v") `+
+				`SELECT max("%v") FROM "updated";`,
+			backfill.PrimaryKeyColumnName,
+			writeQualifiedNamePostgreSQL(builder.schemaName, backfill.TableName),
+			where,
+			backfill.PrimaryKeyColumnName,
+			batchSize,
+			writeQualifiedNamePostgreSQL(builder.schemaName, backfill.TableName),
+			strings.Join(assignments, `,`),
+			backfill.PrimaryKeyColumnName,
+			backfill.PrimaryKeyColumnName,
+			backfill.PrimaryKeyColumnName,
+			backfill.PrimaryKeyColumnName,
+		)
 	}
 
-	builder.queryBuilder.WriteString(`);\n\n`)
-
-	return &tableBuilderPostgreSQL{tableName: name, queryBuilder: builder.queryBuilder}
-}
+	conditions := make([]string, 0, 2)
+	if backfill.Where != "" {
+		conditions = append(conditions, fmt.Sprintf(`(%v)`, backfill.Where))
+	}
+	nextConditions := append([]string{fmt.Sprintf(`"%v" > $1`, backfill.PrimaryKeyColumnName)}, conditions...)
 
-func (builder *builderPostgreSQL) AlterTable(name string) TableBuilder {
-	return &tableBuilderPostgreSQL{tableName: name, queryBuilder: builder.queryBuilder}
-}
+	builder.query.deferStatement(Statement{
+		Query: writeQuery(conditions),
+		Batch: &BatchStatement{
+			NextQuery: writeQuery(nextConditions),
+			Pause:     backfill.Pause,
+		},
+	})
 
-func (builder *builderPostgreSQL) DropTable(name string) Builder {
-	builder.queryBuilder.WriteString(fmt.Sprintf(`DROP TABLE IF EXISTS "%v";\n\n`, name))
 	return builder
 }
 
-func (builder *builderPostgreSQL) TruncateTable(name string) Builder {
-	builder.queryBuilder.WriteString(fmt.Sprintf(`TRUNCATE TABLE "%v";\n\n`, name))
-	return builder
+type tableBuilderPostgreSQL struct {
+	schemaName string
+	tableName  string
+	query      *queryPostgreSQL
 }
 
-type tableBuilderPostgreSQL struct {
-	tableName    string
-	queryBuilder *strings.Builder
+func (builder *tableBuilderPostgreSQL) qualifiedTableName() string {
+	return writeQualifiedNamePostgreSQL(builder.schemaName, builder.tableName)
 }
 
 func (builder *tableBuilderPostgreSQL) Rename(newTableName string) TableBuilder {
-	builder.queryBuilder.WriteString(fmt.Sprintf(`ALTER TABLE IF EXISTS "%v" RENAME TO "%v";\n\n`, builder.tableName, newTableName))
+	builder.query.write(fmt.Sprintf(`ALTER TABLE IF EXISTS %v RENAME TO "%v";`, builder.qualifiedTableName(), newTableName))
 	return builder
 }
 
 func (builder *tableBuilderPostgreSQL) AddColumn(column ColumnDefinition) TableBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" ADD COLUMN IF NOT EXISTS %v;\n\n`,
-			builder.tableName,
-			writeColumnPostgreSQL(column),
+			`ALTER TABLE IF EXISTS %v ADD COLUMN IF NOT EXISTS %v;`,
+			builder.qualifiedTableName(),
+			writeColumnPostgreSQL(builder.schemaName, column),
 		),
 	)
 
+	builder.writeColumnAttributes(column)
+
 	return builder
 }
 
+// STORAGE and COMPRESSION are only allowed in a column definition since PostgreSQL 16,
+// so they are set after the column exists like the comment
+func (builder *tableBuilderPostgreSQL) writeColumnAttributes(column ColumnDefinition) {
+	if column.Storage != ColumnStorageDefault || column.Compression != "" {
+		builder.AlterColumn(column.Name, func(alterColumnBuilder AlterColumnBuilder) {
+			if column.Storage != ColumnStorageDefault {
+				alterColumnBuilder.SetStorage(column.Storage)
+			}
+			if column.Compression != "" {
+				alterColumnBuilder.SetCompression(column.Compression)
+			}
+		})
+	}
+	if column.Comment != "" {
+		builder.SetColumnComment(column.Name, column.Comment)
+	}
+}
+
 func (builder *tableBuilderPostgreSQL) AddConstraint(constraint ConstraintDefinition) TableBuilder {
-	builder.queryBuilder.WriteString(
+	// NOT VALID skips the scan of existing rows, which is only allowed
+	// for foreign key and check constraints
+	notValid := ``
+	if constraint.NotValid && (constraint.Type == ConstraintForeignKey || constraint.Type == ConstraintCheck) {
+		notValid = ` NOT VALID`
+	}
+
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" ADD %v;\n\n`,
-			builder.tableName,
-			writeConstraintPostgreSQL(constraint),
+			`ALTER TABLE IF EXISTS %v ADD %v%v;`,
+			builder.qualifiedTableName(),
+			writeConstraintPostgreSQL(builder.schemaName, constraint),
+			notValid,
 		),
 	)
 
@@ -194,44 +1192,68 @@ func (builder *tableBuilderPostgreSQL) AddConstraint(constraint ConstraintDefini
 }
 
 func (builder *tableBuilderPostgreSQL) AddIndex(index IndexDefinition) TableBuilder {
-	builder.queryBuilder.WriteString(`CREATE `)
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(`CREATE `)
 	if index.Unique {
-		builder.queryBuilder.WriteString(`UNIQUE `)
+		queryBuilder.WriteString(`UNIQUE `)
+	}
+	queryBuilder.WriteString(`INDEX `)
+	if index.Concurrently {
+		queryBuilder.WriteString(`CONCURRENTLY `)
 	}
-	builder.queryBuilder.WriteString(`INDEX `)
 	if index.Name != "" {
-		builder.queryBuilder.WriteString(fmt.Sprintf(`IF NOT EXISTS "%v" `, index.Name))
+		queryBuilder.WriteString(fmt.Sprintf(`IF NOT EXISTS "%v" `, index.Name))
 	}
 
-	builder.queryBuilder.WriteString(fmt.Sprintf(`ON "%v" (`, builder.tableName))
-	columnNamesLength := len(index.ColumnNames)
-	for i, columnName := range index.ColumnNames {
-		builder.queryBuilder.WriteString(fmt.Sprintf(`"%v"`, columnName))
-		if i+1 < columnNamesLength {
-			builder.queryBuilder.WriteString(`,`)
-		}
+	queryBuilder.WriteString(fmt.Sprintf(`ON %v`, builder.qualifiedTableName()))
+	if method := writeIndexMethodPostgreSQL(index.Method); method != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` USING %v`, method))
+	}
+
+	keys := make([]string, 0, len(index.Columns)+1)
+	if len(index.ColumnNames) > 0 {
+		keys = append(keys, writeNamesPostgreSQL(index.ColumnNames))
+	}
+	for _, column := range index.Columns {
+		keys = append(keys, writeIndexColumnPostgreSQL(column))
+	}
+	queryBuilder.WriteString(fmt.Sprintf(` (%v)`, strings.Join(keys, `,`)))
+
+	if len(index.IncludeColumnNames) > 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` INCLUDE (%v)`, writeNamesPostgreSQL(index.IncludeColumnNames)))
+	}
+
+	if len(index.StorageParameters) > 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` WITH (%v)`, writeStorageParametersPostgreSQL(index.StorageParameters)))
 	}
-	builder.queryBuilder.WriteString(`)`)
 
 	if index.OnExpression != "" {
-		builder.queryBuilder.WriteString(fmt.Sprintf(` WHERE %v`, index.OnExpression))
+		queryBuilder.WriteString(fmt.Sprintf(` WHERE %v`, index.OnExpression))
 	}
 
-	builder.queryBuilder.WriteString(`\n\n`)
+	queryBuilder.WriteString(`;`)
+
+	// CREATE INDEX CONCURRENTLY cannot run inside a transaction block
+	if index.Concurrently {
+		builder.query.writeDeferred(queryBuilder.String())
+	} else {
+		builder.query.write(queryBuilder.String())
+	}
 
 	return builder
 }
 
 func (builder *tableBuilderPostgreSQL) AlterColumn(columnName string, callback func(alterColumnBuilder AlterColumnBuilder)) TableBuilder {
-	callback(&alterColumnBuilderPostgreSQL{tableName: builder.tableName, columnName: columnName, queryBuilder: builder.queryBuilder})
+	callback(&alterColumnBuilderPostgreSQL{schemaName: builder.schemaName, tableName: builder.tableName, columnName: columnName, query: builder.query})
 	return builder
 }
 
 func (builder *tableBuilderPostgreSQL) DropColumn(columnName string) TableBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" DROP COLUMN IF EXISTS "%v";\n\n`,
-			builder.tableName,
+			`ALTER TABLE IF EXISTS %v DROP COLUMN IF EXISTS "%v";`,
+			builder.qualifiedTableName(),
 			columnName,
 		),
 	)
@@ -240,10 +1262,10 @@ func (builder *tableBuilderPostgreSQL) DropColumn(columnName string) TableBuilde
 }
 
 func (builder *tableBuilderPostgreSQL) DropConstraint(constraintName string) TableBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" DROP CONSTRAINT IF EXISTS "%v";\n\n`,
-			builder.tableName,
+			`ALTER TABLE IF EXISTS %v DROP CONSTRAINT IF EXISTS "%v";`,
+			builder.qualifiedTableName(),
 			constraintName,
 		),
 	)
@@ -252,10 +1274,22 @@ func (builder *tableBuilderPostgreSQL) DropConstraint(constraintName string) Tab
 }
 
 func (builder *tableBuilderPostgreSQL) DropIndex(indexName string) TableBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
+		fmt.Sprintf(
+			`DROP INDEX IF EXISTS %v;`,
+			writeQualifiedNamePostgreSQL(builder.schemaName, indexName),
+		),
+	)
+
+	return builder
+}
+
+func (builder *tableBuilderPostgreSQL) ValidateConstraint(constraintName string) TableBuilder {
+	builder.query.write(
 		fmt.Sprintf(
-			`DROP INDEX IF EXISTS "%v";\n\n`,
-			indexName,
+			`ALTER TABLE IF EXISTS %v VALIDATE CONSTRAINT "%v";`,
+			builder.qualifiedTableName(),
+			constraintName,
 		),
 	)
 
@@ -263,10 +1297,10 @@ func (builder *tableBuilderPostgreSQL) DropIndex(indexName string) TableBuilder
 }
 
 func (builder *tableBuilderPostgreSQL) RenameColumn(oldColumnName string, newColumnName string) TableBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" RENAME COLUMN "%v" TO "%v";\n\n`,
-			builder.tableName,
+			`ALTER TABLE IF EXISTS %v RENAME COLUMN "%v" TO "%v";`,
+			builder.qualifiedTableName(),
 			oldColumnName,
 			newColumnName,
 		),
@@ -276,10 +1310,10 @@ func (builder *tableBuilderPostgreSQL) RenameColumn(oldColumnName string, newCol
 }
 
 func (builder *tableBuilderPostgreSQL) RenameConstraint(oldConstraintName string, newConstraintName string) TableBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" RENAME CONSTRAINT "%v" TO "%v";\n\n`,
-			builder.tableName,
+			`ALTER TABLE IF EXISTS %v RENAME CONSTRAINT "%v" TO "%v";`,
+			builder.qualifiedTableName(),
 			oldConstraintName,
 			newConstraintName,
 		),
@@ -289,10 +1323,10 @@ func (builder *tableBuilderPostgreSQL) RenameConstraint(oldConstraintName string
 }
 
 func (builder *tableBuilderPostgreSQL) RenameIndex(oldIndexName string, newIndexName string) TableBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER INDEX IF EXISTS "%v" RENAME TO "%v";\n\n`,
-			oldIndexName,
+			`ALTER INDEX IF EXISTS %v RENAME TO "%v";`,
+			writeQualifiedNamePostgreSQL(builder.schemaName, oldIndexName),
 			newIndexName,
 		),
 	)
@@ -300,8 +1334,185 @@ func (builder *tableBuilderPostgreSQL) RenameIndex(oldIndexName string, newIndex
 	return builder
 }
 
+func (builder *tableBuilderPostgreSQL) EnableRowLevelSecurity() TableBuilder {
+	builder.query.write(fmt.Sprintf(`ALTER TABLE IF EXISTS %v ENABLE ROW LEVEL SECURITY;`, builder.qualifiedTableName()))
+	return builder
+}
+
+func (builder *tableBuilderPostgreSQL) DisableRowLevelSecurity() TableBuilder {
+	builder.query.write(fmt.Sprintf(`ALTER TABLE IF EXISTS %v DISABLE ROW LEVEL SECURITY;`, builder.qualifiedTableName()))
+	return builder
+}
+
+func (builder *tableBuilderPostgreSQL) ForceRowLevelSecurity() TableBuilder {
+	builder.query.write(fmt.Sprintf(`ALTER TABLE IF EXISTS %v FORCE ROW LEVEL SECURITY;`, builder.qualifiedTableName()))
+	return builder
+}
+
+func (builder *tableBuilderPostgreSQL) NoForceRowLevelSecurity() TableBuilder {
+	builder.query.write(fmt.Sprintf(`ALTER TABLE IF EXISTS %v NO FORCE ROW LEVEL SECURITY;`, builder.qualifiedTableName()))
+	return builder
+}
+
+func (builder *tableBuilderPostgreSQL) CreatePolicy(policy PolicyDefinition) TableBuilder {
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(fmt.Sprintf(`CREATE POLICY "%v" ON %v`, policy.Name, builder.qualifiedTableName()))
+	if policy.Restrictive {
+		queryBuilder.WriteString(` AS RESTRICTIVE`)
+	}
+	switch policy.Command {
+	case PolicySelect:
+		queryBuilder.WriteString(` FOR SELECT`)
+	case PolicyInsert:
+		queryBuilder.WriteString(` FOR INSERT`)
+	case PolicyUpdate:
+		queryBuilder.WriteString(` FOR UPDATE`)
+	case PolicyDelete:
+		queryBuilder.WriteString(` FOR DELETE`)
+	}
+	if len(policy.RoleNames) > 0 {
+		queryBuilder.WriteString(fmt.Sprintf(` TO %v`, writeRoleNamesPostgreSQL(policy.RoleNames)))
+	}
+	if policy.UsingExpression != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` USING (%v)`, policy.UsingExpression))
+	}
+	if policy.WithCheckExpression != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` WITH CHECK (%v)`, policy.WithCheckExpression))
+	}
+	queryBuilder.WriteString(`;`)
+
+	builder.query.write(queryBuilder.String())
+
+	return builder
+}
+
+func (builder *tableBuilderPostgreSQL) DropPolicy(policyName string) TableBuilder {
+	builder.query.write(
+		fmt.Sprintf(
+			`DROP POLICY IF EXISTS "%v" ON %v;`,
+			policyName,
+			builder.qualifiedTableName(),
+		),
+	)
+
+	return builder
+}
+
+type enumBuilderPostgreSQL struct {
+	schemaName string
+	enumName   string
+	query      *queryPostgreSQL
+}
+
+// ALTER TYPE ... ADD VALUE cannot run inside a transaction block before PostgreSQL 12,
+// and even after that the new value cannot be used until the transaction commits,
+// so it is always executed after the transaction
+func (builder *enumBuilderPostgreSQL) AddValue(value string) EnumBuilder {
+	builder.query.writeDeferred(
+		fmt.Sprintf(
+			`ALTER TYPE %v ADD VALUE IF NOT EXISTS %v;`,
+			writeQualifiedNamePostgreSQL(builder.schemaName, builder.enumName),
+			writeLiteralPostgreSQL(value),
+		),
+	)
+	return builder
+}
+
+func (builder *enumBuilderPostgreSQL) AddValueBefore(value string, beforeValue string) EnumBuilder {
+	builder.query.writeDeferred(
+		fmt.Sprintf(
+			`ALTER TYPE %v ADD VALUE IF NOT EXISTS %v BEFORE %v;`,
+			writeQualifiedNamePostgreSQL(builder.schemaName, builder.enumName),
+			writeLiteralPostgreSQL(value),
+			writeLiteralPostgreSQL(beforeValue),
+		),
+	)
+	return builder
+}
+
+func (builder *enumBuilderPostgreSQL) AddValueAfter(value string, afterValue string) EnumBuilder {
+	builder.query.writeDeferred(
+		fmt.Sprintf(
+			`ALTER TYPE %v ADD VALUE IF NOT EXISTS %v AFTER %v;`,
+			writeQualifiedNamePostgreSQL(builder.schemaName, builder.enumName),
+			writeLiteralPostgreSQL(value),
+			writeLiteralPostgreSQL(afterValue),
+		),
+	)
+	return builder
+}
+
+func (builder *enumBuilderPostgreSQL) RenameValue(oldValue string, newValue string) EnumBuilder {
+	builder.query.write(
+		fmt.Sprintf(
+			`ALTER TYPE %v RENAME VALUE %v TO %v;`,
+			writeQualifiedNamePostgreSQL(builder.schemaName, builder.enumName),
+			writeLiteralPostgreSQL(oldValue),
+			writeLiteralPostgreSQL(newValue),
+		),
+	)
+	return builder
+}
+
+type sequenceBuilderPostgreSQL struct {
+	schemaName   string
+	sequenceName string
+	query        *queryPostgreSQL
+}
+
+func (builder *sequenceBuilderPostgreSQL) alter(option string) SequenceBuilder {
+	builder.query.write(fmt.Sprintf(`ALTER SEQUENCE IF EXISTS %v %v;`, writeQualifiedNamePostgreSQL(builder.schemaName, builder.sequenceName), option))
+	return builder
+}
+
+func (builder *sequenceBuilderPostgreSQL) SetType(sequenceType types.Type) SequenceBuilder {
+	return builder.alter(fmt.Sprintf(`AS %v`, writeTypePostgreSQL(sequenceType)))
+}
+
+func (builder *sequenceBuilderPostgreSQL) SetIncrement(increment int64) SequenceBuilder {
+	return builder.alter(fmt.Sprintf(`INCREMENT BY %v`, increment))
+}
+
+func (builder *sequenceBuilderPostgreSQL) SetMinValue(minValue int64) SequenceBuilder {
+	return builder.alter(fmt.Sprintf(`MINVALUE %v`, minValue))
+}
+
+func (builder *sequenceBuilderPostgreSQL) SetMaxValue(maxValue int64) SequenceBuilder {
+	return builder.alter(fmt.Sprintf(`MAXVALUE %v`, maxValue))
+}
+
+func (builder *sequenceBuilderPostgreSQL) SetStart(start int64) SequenceBuilder {
+	return builder.alter(fmt.Sprintf(`START WITH %v`, start))
+}
+
+func (builder *sequenceBuilderPostgreSQL) SetCache(cache int64) SequenceBuilder {
+	return builder.alter(fmt.Sprintf(`CACHE %v`, cache))
+}
+
+func (builder *sequenceBuilderPostgreSQL) SetCycle(enableCycle bool) SequenceBuilder {
+	if enableCycle {
+		return builder.alter(`CYCLE`)
+	}
+	return builder.alter(`NO CYCLE`)
+}
+
+func (builder *sequenceBuilderPostgreSQL) SetOwnedBy(tableName string, columnName string) SequenceBuilder {
+	return builder.alter(fmt.Sprintf(`OWNED BY %v."%v"`, writeQualifiedNamePostgreSQL(builder.schemaName, tableName), columnName))
+}
+
+func (builder *sequenceBuilderPostgreSQL) DropOwnedBy() SequenceBuilder {
+	return builder.alter(`OWNED BY NONE`)
+}
+
+func (builder *sequenceBuilderPostgreSQL) Rename(newSequenceName string) SequenceBuilder {
+	builder.alter(fmt.Sprintf(`RENAME TO "%v"`, newSequenceName))
+	builder.sequenceName = newSequenceName
+	return builder
+}
+
 type columnBuilderPostgreSQL struct {
-	queryBuilder *strings.Builder
+	query *queryPostgreSQL
 
 	definition ColumnDefinition
 }
@@ -311,8 +1522,8 @@ func (builder *columnBuilderPostgreSQL) WithName(name string) ColumnBuilder {
 	return builder
 }
 
-func (builder *columnBuilderPostgreSQL) WithType(typeName string) ColumnBuilder {
-	builder.definition.Type = typeName
+func (builder *columnBuilderPostgreSQL) WithType(columnType types.Type) ColumnBuilder {
+	builder.definition.Type = columnType
 	return builder
 }
 
@@ -353,33 +1564,202 @@ func (builder *columnBuilderPostgreSQL) IsAutoIncrement(enableAutoIncrement bool
 	return builder
 }
 
+func (builder *columnBuilderPostgreSQL) WithIdentity(byDefault bool, start int64, increment int64) ColumnBuilder {
+	builder.definition.AutoIncrement = true
+	builder.definition.IdentityByDefault = byDefault
+	builder.definition.IdentityStart = start
+	builder.definition.IdentityIncrement = increment
+	return builder
+}
+
+func (builder *columnBuilderPostgreSQL) WithComment(comment string) ColumnBuilder {
+	builder.definition.Comment = comment
+	return builder
+}
+
+func (builder *columnBuilderPostgreSQL) WithGeneratedExpression(expression string) ColumnBuilder {
+	builder.definition.GeneratedExpression = expression
+	return builder
+}
+
+func (builder *columnBuilderPostgreSQL) WithCollation(collation string) ColumnBuilder {
+	builder.definition.Collation = collation
+	return builder
+}
+
+func (builder *columnBuilderPostgreSQL) WithStorage(storage ColumnStorage) ColumnBuilder {
+	builder.definition.Storage = storage
+	return builder
+}
+
+func (builder *columnBuilderPostgreSQL) WithCompression(method string) ColumnBuilder {
+	builder.definition.Compression = method
+	return builder
+}
+
 func (builder *columnBuilderPostgreSQL) Build() ColumnDefinition {
 	return builder.definition
 }
 
+func (builder *tableBuilderPostgreSQL) AttachPartition(partitionName string, bounds PartitionBounds) TableBuilder {
+	builder.query.write(
+		fmt.Sprintf(
+			`ALTER TABLE IF EXISTS %v ATTACH PARTITION %v %v;`,
+			builder.qualifiedTableName(),
+			writeQualifiedNamePostgreSQL(builder.schemaName, partitionName),
+			writePartitionBoundsPostgreSQL(bounds),
+		),
+	)
+
+	return builder
+}
+
+func (builder *tableBuilderPostgreSQL) DetachPartition(partitionName string, concurrently bool) TableBuilder {
+	// DETACH PARTITION CONCURRENTLY cannot run inside a transaction block
+	if concurrently {
+		builder.query.writeDeferred(
+			fmt.Sprintf(
+				`ALTER TABLE IF EXISTS %v DETACH PARTITION %v CONCURRENTLY;`,
+				builder.qualifiedTableName(),
+				writeQualifiedNamePostgreSQL(builder.schemaName, partitionName),
+			),
+		)
+	} else {
+		builder.query.write(
+			fmt.Sprintf(
+				`ALTER TABLE IF EXISTS %v DETACH PARTITION %v;`,
+				builder.qualifiedTableName(),
+				writeQualifiedNamePostgreSQL(builder.schemaName, partitionName),
+			),
+		)
+	}
+
+	return builder
+}
+
+func (builder *tableBuilderPostgreSQL) SetComment(comment string) TableBuilder {
+	if comment != "" {
+		builder.query.write(fmt.Sprintf(`COMMENT ON TABLE %v IS %v;`, builder.qualifiedTableName(), writeLiteralPostgreSQL(comment)))
+	} else {
+		builder.query.write(fmt.Sprintf(`COMMENT ON TABLE %v IS NULL;`, builder.qualifiedTableName()))
+	}
+	return builder
+}
+
+func (builder *tableBuilderPostgreSQL) SetColumnComment(columnName string, comment string) TableBuilder {
+	if comment != "" {
+		builder.query.write(
+			fmt.Sprintf(
+				`COMMENT ON COLUMN %v."%v" IS %v;`,
+				builder.qualifiedTableName(),
+				columnName,
+				writeLiteralPostgreSQL(comment),
+			),
+		)
+	} else {
+		builder.query.write(fmt.Sprintf(`COMMENT ON COLUMN %v."%v" IS NULL;`, builder.qualifiedTableName(), columnName))
+	}
+	return builder
+}
+
+func (builder *tableBuilderPostgreSQL) CreateTrigger(trigger TriggerDefinition) TableBuilder {
+	queryBuilder := new(strings.Builder)
+
+	queryBuilder.WriteString(fmt.Sprintf(`CREATE TRIGGER "%v"`, trigger.Name))
+	switch trigger.Timing {
+	case TriggerBefore:
+		queryBuilder.WriteString(` BEFORE`)
+	case TriggerAfter:
+		queryBuilder.WriteString(` AFTER`)
+	case TriggerInsteadOf:
+		queryBuilder.WriteString(` INSTEAD OF`)
+	}
+
+	events := make([]string, 0, len(trigger.Events))
+	for _, event := range trigger.Events {
+		switch event {
+		case TriggerInsert:
+			events = append(events, `INSERT`)
+		case TriggerUpdate:
+			if len(trigger.UpdateColumnNames) > 0 {
+				events = append(events, fmt.Sprintf(`UPDATE OF %v`, writeNamesPostgreSQL(trigger.UpdateColumnNames)))
+			} else {
+				events = append(events, `UPDATE`)
+			}
+		case TriggerDelete:
+			events = append(events, `DELETE`)
+		case TriggerTruncate:
+			events = append(events, `TRUNCATE`)
+		}
+	}
+	queryBuilder.WriteString(fmt.Sprintf(` %v ON %v`, strings.Join(events, ` OR `), builder.qualifiedTableName()))
+
+	if trigger.ForEachRow {
+		queryBuilder.WriteString(` FOR EACH ROW`)
+	} else {
+		queryBuilder.WriteString(` FOR EACH STATEMENT`)
+	}
+	if trigger.WhenCondition != "" {
+		queryBuilder.WriteString(fmt.Sprintf(` WHEN (%v)`, trigger.WhenCondition))
+	}
+
+	functionArguments := make([]string, 0, len(trigger.FunctionArguments))
+	for _, functionArgument := range trigger.FunctionArguments {
+		functionArguments = append(functionArguments, writeLiteralPostgreSQL(functionArgument))
+	}
+	queryBuilder.WriteString(
+		fmt.Sprintf(
+			` EXECUTE FUNCTION %v(%v);`,
+			writeReferenceNamePostgreSQL(builder.schemaName, trigger.FunctionSchemaName, trigger.FunctionName),
+			strings.Join(functionArguments, `,`),
+		),
+	)
+
+	builder.query.write(queryBuilder.String())
+
+	return builder
+}
+
+func (builder *tableBuilderPostgreSQL) DropTrigger(triggerName string) TableBuilder {
+	builder.query.write(
+		fmt.Sprintf(
+			`DROP TRIGGER IF EXISTS "%v" ON %v;`,
+			triggerName,
+			builder.qualifiedTableName(),
+		),
+	)
+
+	return builder
+}
+
 type alterColumnBuilderPostgreSQL struct {
-	tableName    string
-	columnName   string
-	queryBuilder *strings.Builder
+	schemaName string
+	tableName  string
+	columnName string
+	query      *queryPostgreSQL
+}
+
+func (builder *alterColumnBuilderPostgreSQL) qualifiedTableName() string {
+	return writeQualifiedNamePostgreSQL(builder.schemaName, builder.tableName)
 }
 
-func (builder *alterColumnBuilderPostgreSQL) AlterType(typeName string) AlterColumnBuilder {
-	builder.queryBuilder.WriteString(
+func (builder *alterColumnBuilderPostgreSQL) AlterType(columnType types.Type) AlterColumnBuilder {
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" ALTER COLUMN "%v" TYPE %v;\n\n`,
-			builder.tableName,
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" TYPE %v;`,
+			builder.qualifiedTableName(),
 			builder.columnName,
-			typeName,
+			writeTypePostgreSQL(columnType),
 		),
 	)
 	return builder
 }
 
 func (builder *alterColumnBuilderPostgreSQL) AlterDefault(expression string) AlterColumnBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" ALTER COLUMN "%v" SET DEFAULT %v;\n\n`,
-			builder.tableName,
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" SET DEFAULT %v;`,
+			builder.qualifiedTableName(),
 			builder.columnName,
 			expression,
 		),
@@ -388,10 +1768,10 @@ func (builder *alterColumnBuilderPostgreSQL) AlterDefault(expression string) Alt
 }
 
 func (builder *alterColumnBuilderPostgreSQL) DropDefault() AlterColumnBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" ALTER COLUMN "%v" DROP DEFAULT;\n\n`,
-			builder.tableName,
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" DROP DEFAULT;`,
+			builder.qualifiedTableName(),
 			builder.columnName,
 		),
 	)
@@ -399,10 +1779,10 @@ func (builder *alterColumnBuilderPostgreSQL) DropDefault() AlterColumnBuilder {
 }
 
 func (builder *alterColumnBuilderPostgreSQL) SetNullable() AlterColumnBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" ALTER COLUMN "%v" DROP NOT NULL;\n\n`,
-			builder.tableName,
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" DROP NOT NULL;`,
+			builder.qualifiedTableName(),
 			builder.columnName,
 		),
 	)
@@ -410,10 +1790,10 @@ func (builder *alterColumnBuilderPostgreSQL) SetNullable() AlterColumnBuilder {
 }
 
 func (builder *alterColumnBuilderPostgreSQL) DropNullable() AlterColumnBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" ALTER COLUMN "%v" SET NOT NULL;\n\n`,
-			builder.tableName,
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" SET NOT NULL;`,
+			builder.qualifiedTableName(),
 			builder.columnName,
 		),
 	)
@@ -421,10 +1801,10 @@ func (builder *alterColumnBuilderPostgreSQL) DropNullable() AlterColumnBuilder {
 }
 
 func (builder *alterColumnBuilderPostgreSQL) SetAutoIncrement() AlterColumnBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" ALTER COLUMN "%v" ADD GENERATED ALWAYS AS IDENTITY;\n\n`,
-			builder.tableName,
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" ADD GENERATED ALWAYS AS IDENTITY;`,
+			builder.qualifiedTableName(),
 			builder.columnName,
 		),
 	)
@@ -432,11 +1812,79 @@ func (builder *alterColumnBuilderPostgreSQL) SetAutoIncrement() AlterColumnBuild
 }
 
 func (builder *alterColumnBuilderPostgreSQL) DropAutoIncrement() AlterColumnBuilder {
-	builder.queryBuilder.WriteString(
+	builder.query.write(
+		fmt.Sprintf(
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" DROP IDENTITY IF EXISTS;`,
+			builder.qualifiedTableName(),
+			builder.columnName,
+		),
+	)
+	return builder
+}
+
+// PostgreSQL has no dedicated statement to change the collation of a column,
+// so the column type has to be restated together with the new collation
+func (builder *alterColumnBuilderPostgreSQL) SetCollation(columnType types.Type, collation string) AlterColumnBuilder {
+	builder.query.write(
+		fmt.Sprintf(
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" TYPE %v COLLATE "%v";`,
+			builder.qualifiedTableName(),
+			builder.columnName,
+			writeTypePostgreSQL(columnType),
+			collation,
+		),
+	)
+	return builder
+}
+
+func (builder *alterColumnBuilderPostgreSQL) DropExpression() AlterColumnBuilder {
+	builder.query.write(
+		fmt.Sprintf(
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" DROP EXPRESSION IF EXISTS;`,
+			builder.qualifiedTableName(),
+			builder.columnName,
+		),
+	)
+	return builder
+}
+
+func (builder *alterColumnBuilderPostgreSQL) SetStorage(storage ColumnStorage) AlterColumnBuilder {
+	// SET STORAGE DEFAULT needs PostgreSQL 16, and the default of the type
+	// is not known here, so ColumnStorageDefault leaves the storage as it is
+	storageName := writeColumnStoragePostgreSQL(storage)
+	if storageName == "" {
+		return builder
+	}
+	builder.query.write(
+		fmt.Sprintf(
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" SET STORAGE %v;`,
+			builder.qualifiedTableName(),
+			builder.columnName,
+			storageName,
+		),
+	)
+	return builder
+}
+
+func (builder *alterColumnBuilderPostgreSQL) SetCompression(method string) AlterColumnBuilder {
+	builder.query.write(
+		fmt.Sprintf(
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" SET COMPRESSION %v;`,
+			builder.qualifiedTableName(),
+			builder.columnName,
+			method,
+		),
+	)
+	return builder
+}
+
+func (builder *alterColumnBuilderPostgreSQL) SetStatistics(target int) AlterColumnBuilder {
+	builder.query.write(
 		fmt.Sprintf(
-			`ALTER TABLE IF EXISTS "%v" ALTER COLUMN "%v" DROP IDENTITY IF EXISTS;\n\n`,
-			builder.tableName,
+			`ALTER TABLE IF EXISTS %v ALTER COLUMN "%v" SET STATISTICS %v;`,
+			builder.qualifiedTableName(),
 			builder.columnName,
+			target,
 		),
 	)
 	return builder
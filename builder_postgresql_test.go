@@ -0,0 +1,899 @@
+package gomimi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ItsMalma/gomimi/types"
+)
+
+type builderTest struct {
+	name     string
+	build    func(builder Builder)
+	want     []string
+	deferred []string
+}
+
+func statementQueries(statements []Statement) []string {
+	queries := make([]string, len(statements))
+	for index, statement := range statements {
+		queries[index] = statement.Query
+	}
+	return queries
+}
+
+func equalQueries(got []string, want []string) bool {
+	return len(got) == 0 && len(want) == 0 || reflect.DeepEqual(got, want)
+}
+
+func runBuilderTests(t *testing.T, tests []builderTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder := NewBuilderPostgreSQL()
+			test.build(builder)
+			if got := statementQueries(builder.Statements()); !equalQueries(got, test.want) {
+				t.Errorf("queries = %q, want %q", got, test.want)
+			}
+			if got := statementQueries(builder.Deferred()); !equalQueries(got, test.deferred) {
+				t.Errorf("deferred queries = %q, want %q", got, test.deferred)
+			}
+		})
+	}
+}
+
+func TestBuilderPostgreSQLSchemaQualification(t *testing.T) {
+	userReference := ColumnDefinition{Name: "user_id", Type: types.BigInt(), Reference: true, ReferenceTableName: "users", ReferenceColumnNames: []string{"id"}}
+	publicUserReference := userReference
+	publicUserReference.ReferenceSchemaName = "public"
+	userForeignKey := ConstraintDefinition{Name: "posts_user_id_fkey", Type: ConstraintForeignKey, ColumnNames: []string{"user_id"}, ReferenceTableName: "users", ReferenceColumnNames: []string{"id"}}
+	publicUserForeignKey := userForeignKey
+	publicUserForeignKey.ReferenceSchemaName = "public"
+	touchTrigger := TriggerDefinition{Name: "posts_touch", Timing: TriggerBefore, Events: []TriggerEvent{TriggerUpdate}, ForEachRow: true, FunctionName: "touch"}
+	publicTouchTrigger := touchTrigger
+	publicTouchTrigger.FunctionSchemaName = "public"
+
+	runBuilderTests(t, []builderTest{
+		{
+			name: "reference without a schema",
+			build: func(builder Builder) {
+				builder.CreateTable("posts", []ColumnDefinition{userReference}, []ConstraintDefinition{userForeignKey})
+			},
+			want: []string{`CREATE TABLE IF NOT EXISTS "posts" ("user_id" BIGINT NOT NULL REFERENCES "users" ("id"),CONSTRAINT "posts_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id"));`},
+		},
+		{
+			name: "reference in the schema of the builder",
+			build: func(builder Builder) {
+				builder.InSchema("app").CreateTable("posts", []ColumnDefinition{userReference}, []ConstraintDefinition{userForeignKey})
+			},
+			want: []string{`CREATE TABLE IF NOT EXISTS "app"."posts" ("user_id" BIGINT NOT NULL REFERENCES "app"."users" ("id"),CONSTRAINT "posts_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "app"."users" ("id"));`},
+		},
+		{
+			name: "reference in another schema",
+			build: func(builder Builder) {
+				builder.InSchema("app").CreateTable("posts", []ColumnDefinition{publicUserReference}, []ConstraintDefinition{publicUserForeignKey})
+			},
+			want: []string{`CREATE TABLE IF NOT EXISTS "app"."posts" ("user_id" BIGINT NOT NULL REFERENCES "public"."users" ("id"),CONSTRAINT "posts_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id"));`},
+		},
+		{
+			name: "added reference in another schema",
+			build: func(builder Builder) {
+				builder.InSchema("app").AlterTable("posts").AddConstraint(publicUserForeignKey)
+			},
+			want: []string{`ALTER TABLE IF EXISTS "app"."posts" ADD CONSTRAINT "posts_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id");`},
+		},
+		{
+			name: "trigger function in the schema of the builder",
+			build: func(builder Builder) {
+				builder.InSchema("app").AlterTable("posts").CreateTrigger(touchTrigger)
+			},
+			want: []string{`CREATE TRIGGER "posts_touch" BEFORE UPDATE ON "app"."posts" FOR EACH ROW EXECUTE FUNCTION "app"."touch"();`},
+		},
+		{
+			name: "trigger function in another schema",
+			build: func(builder Builder) {
+				builder.InSchema("app").AlterTable("posts").CreateTrigger(publicTouchTrigger)
+			},
+			want: []string{`CREATE TRIGGER "posts_touch" BEFORE UPDATE ON "app"."posts" FOR EACH ROW EXECUTE FUNCTION "public"."touch"();`},
+		},
+	})
+}
+
+func TestWriteTypePostgreSQL(t *testing.T) {
+	tests := []struct {
+		columnType types.Type
+		want       string
+	}{
+		{types.SmallInt(), `SMALLINT`},
+		{types.BigInt(), `BIGINT`},
+		{types.Double(), `DOUBLE PRECISION`},
+		{types.Decimal(10, 2), `NUMERIC(10,2)`},
+		{types.Decimal(0, 0), `NUMERIC`},
+		{types.Char(2), `CHAR(2)`},
+		{types.Varchar(255), `VARCHAR(255)`},
+		{types.Varchar(0), `VARCHAR`},
+		{types.Binary(), `BYTEA`},
+		{types.Time(true), `TIMETZ`},
+		{types.Timestamp(false), `TIMESTAMP`},
+		{types.Timestamp(true), `TIMESTAMPTZ`},
+		{types.JSON(), `JSONB`},
+		{types.UUID(), `UUID`},
+		{types.Array(types.Integer()), `INTEGER[]`},
+		{types.Array(types.Array(types.Text())), `TEXT[][]`},
+		{types.Raw("CITEXT"), `CITEXT`},
+	}
+
+	for _, test := range tests {
+		if got := writeTypePostgreSQL(test.columnType); got != test.want {
+			t.Errorf("writeTypePostgreSQL(%+v) = %v, want %v", test.columnType, got, test.want)
+		}
+	}
+}
+
+func TestBuilderPostgreSQLIndexes(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{
+			name: "unique index",
+			build: func(builder Builder) {
+				builder.AlterTable("users").AddIndex(IndexDefinition{Name: "users_email_key", ColumnNames: []string{"email"}, Unique: true})
+			},
+			want: []string{`CREATE UNIQUE INDEX IF NOT EXISTS "users_email_key" ON "users" ("email");`},
+		},
+		{
+			name: "method, sort order and operator class",
+			build: func(builder Builder) {
+				builder.AlterTable("posts").AddIndex(IndexDefinition{
+					Name:   "posts_title_idx",
+					Method: IndexGIN,
+					Columns: []IndexColumnDefinition{
+						{Name: "title", OperatorClass: "gin_trgm_ops"},
+					},
+				})
+				builder.AlterTable("posts").AddIndex(IndexDefinition{
+					Name: "posts_published_at_idx",
+					Columns: []IndexColumnDefinition{
+						{Name: "published_at", Descending: true, Nulls: IndexNullsLast},
+						{Expression: "lower(title)"},
+					},
+				})
+			},
+			want: []string{`CREATE INDEX IF NOT EXISTS "posts_title_idx" ON "posts" USING gin ("title" gin_trgm_ops);`, `CREATE INDEX IF NOT EXISTS "posts_published_at_idx" ON "posts" ("published_at" DESC NULLS LAST,(lower(title)));`},
+		},
+		{
+			name: "include, storage parameters and partial",
+			build: func(builder Builder) {
+				builder.AlterTable("orders").AddIndex(IndexDefinition{
+					Name:               "orders_customer_id_idx",
+					ColumnNames:        []string{"customer_id"},
+					IncludeColumnNames: []string{"total"},
+					StorageParameters:  map[string]string{"fillfactor": "70"},
+					OnExpression:       `"status" = 'open'`,
+				})
+			},
+			want: []string{`CREATE INDEX IF NOT EXISTS "orders_customer_id_idx" ON "orders" ("customer_id") INCLUDE ("total") WITH (fillfactor=70) WHERE "status" = 'open';`},
+		},
+		{
+			name: "concurrently",
+			build: func(builder Builder) {
+				builder.InSchema("app").AlterTable("users").AddIndex(IndexDefinition{Name: "users_name_idx", ColumnNames: []string{"name"}, Concurrently: true})
+			},
+			deferred: []string{`CREATE INDEX CONCURRENTLY IF NOT EXISTS "users_name_idx" ON "app"."users" ("name");`},
+		},
+		{
+			name: "drop and rename",
+			build: func(builder Builder) {
+				builder.InSchema("app").AlterTable("users").RenameIndex("users_name_idx", "users_full_name_idx").DropIndex("users_email_key")
+			},
+			want: []string{`ALTER INDEX IF EXISTS "app"."users_name_idx" RENAME TO "users_full_name_idx";`, `DROP INDEX IF EXISTS "app"."users_email_key";`},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLViews(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{
+			name: "view",
+			build: func(builder Builder) {
+				builder.CreateView(ViewDefinition{Name: "active_users", Query: `SELECT * FROM "users" WHERE "active"`})
+			},
+			want: []string{`CREATE VIEW "active_users" AS SELECT * FROM "users" WHERE "active";`},
+		},
+		{
+			name: "replaced view with columns and check option",
+			build: func(builder Builder) {
+				builder.InSchema("app").CreateOrReplaceView(ViewDefinition{
+					Name:        "active_users",
+					ColumnNames: []string{"id", "name"},
+					Query:       `SELECT "id", "name" FROM "users" WHERE "active"`,
+					CheckOption: ViewCheckCascaded,
+				})
+			},
+			want: []string{`CREATE OR REPLACE VIEW "app"."active_users" ("id","name") AS SELECT "id", "name" FROM "users" WHERE "active" WITH CASCADED CHECK OPTION;`},
+		},
+		{
+			name: "dropped view",
+			build: func(builder Builder) {
+				builder.DropView("active_users")
+			},
+			want: []string{`DROP VIEW IF EXISTS "active_users";`},
+		},
+		{
+			name: "materialized view",
+			build: func(builder Builder) {
+				builder.CreateMaterializedView(MaterializedViewDefinition{Name: "user_counts", ColumnNames: []string{"total"}, Query: `SELECT count(*) FROM "users"`, WithNoData: true})
+				builder.RefreshMaterializedView("user_counts", false)
+				builder.RefreshMaterializedView("user_counts", true)
+				builder.DropMaterializedView("user_counts")
+			},
+			want: []string{
+				`CREATE MATERIALIZED VIEW IF NOT EXISTS "user_counts" ("total") AS SELECT count(*) FROM "users" WITH NO DATA;`,
+				`REFRESH MATERIALIZED VIEW "user_counts";`,
+				`REFRESH MATERIALIZED VIEW CONCURRENTLY "user_counts";`,
+				`DROP MATERIALIZED VIEW IF EXISTS "user_counts";`,
+			},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLEnums(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{
+			name: "enum",
+			build: func(builder Builder) {
+				builder.InSchema("app").CreateEnum("mood", "sad", "happy")
+			},
+			want: []string{`CREATE TYPE "app"."mood" AS ENUM ('sad','happy');`},
+		},
+		{
+			name: "added and renamed values",
+			build: func(builder Builder) {
+				builder.AlterEnum("mood").
+					AddValue("ecstatic").
+					AddValueBefore("ok", "happy").
+					AddValueAfter("content", "ok").
+					RenameValue("sad", "unhappy")
+			},
+			want: []string{`ALTER TYPE "mood" RENAME VALUE 'sad' TO 'unhappy';`},
+			deferred: []string{
+				`ALTER TYPE "mood" ADD VALUE IF NOT EXISTS 'ecstatic';`,
+				`ALTER TYPE "mood" ADD VALUE IF NOT EXISTS 'ok' BEFORE 'happy';`,
+				`ALTER TYPE "mood" ADD VALUE IF NOT EXISTS 'content' AFTER 'ok';`,
+			},
+		},
+		{
+			name: "value with a quote",
+			build: func(builder Builder) {
+				builder.CreateEnum("answer", "don't know")
+			},
+			want: []string{`CREATE TYPE "answer" AS ENUM ('don''t know');`},
+		},
+		{
+			name: "dropped enum",
+			build: func(builder Builder) {
+				builder.DropEnum("mood")
+			},
+			want: []string{`DROP TYPE IF EXISTS "mood";`},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLSequences(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{
+			name: "sequence",
+			build: func(builder Builder) {
+				builder.CreateSequence(SequenceDefinition{Name: "order_number"})
+			},
+			want: []string{`CREATE SEQUENCE IF NOT EXISTS "order_number";`},
+		},
+		{
+			name: "sequence with options",
+			build: func(builder Builder) {
+				builder.InSchema("app").CreateSequence(SequenceDefinition{
+					Name:              "order_number",
+					Type:              types.BigInt(),
+					Start:             1000,
+					Increment:         10,
+					MinValue:          1000,
+					MaxValue:          999999,
+					Cache:             20,
+					Cycle:             true,
+					OwnedByTableName:  "orders",
+					OwnedByColumnName: "number",
+				})
+			},
+			want: []string{`CREATE SEQUENCE IF NOT EXISTS "app"."order_number" AS BIGINT INCREMENT BY 10 MINVALUE 1000 MAXVALUE 999999 START WITH 1000 CACHE 20 CYCLE OWNED BY "app"."orders"."number";`},
+		},
+		{
+			name: "altered sequence",
+			build: func(builder Builder) {
+				builder.AlterSequence("order_number").
+					SetType(types.Integer()).
+					SetIncrement(5).
+					SetMinValue(1).
+					SetMaxValue(1000).
+					SetStart(1).
+					SetCache(1).
+					SetCycle(false).
+					SetOwnedBy("orders", "number").
+					DropOwnedBy().
+					Rename("order_sequence")
+			},
+			want: []string{
+				`ALTER SEQUENCE IF EXISTS "order_number" AS INTEGER;`,
+				`ALTER SEQUENCE IF EXISTS "order_number" INCREMENT BY 5;`,
+				`ALTER SEQUENCE IF EXISTS "order_number" MINVALUE 1;`,
+				`ALTER SEQUENCE IF EXISTS "order_number" MAXVALUE 1000;`,
+				`ALTER SEQUENCE IF EXISTS "order_number" START WITH 1;`,
+				`ALTER SEQUENCE IF EXISTS "order_number" CACHE 1;`,
+				`ALTER SEQUENCE IF EXISTS "order_number" NO CYCLE;`,
+				`ALTER SEQUENCE IF EXISTS "order_number" OWNED BY "orders"."number";`,
+				`ALTER SEQUENCE IF EXISTS "order_number" OWNED BY NONE;`,
+				`ALTER SEQUENCE IF EXISTS "order_number" RENAME TO "order_sequence";`,
+			},
+		},
+		{
+			name: "restarted and dropped sequence",
+			build: func(builder Builder) {
+				builder.RestartSequence("order_number", 1)
+				builder.DropSequence("order_number")
+			},
+			want: []string{`ALTER SEQUENCE IF EXISTS "order_number" RESTART WITH 1;`, `DROP SEQUENCE IF EXISTS "order_number";`},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLFunctions(t *testing.T) {
+	touch := FunctionDefinition{
+		Name:     "touch",
+		Returns:  types.Raw("TRIGGER"),
+		Language: "plpgsql",
+		Body:     `BEGIN NEW."updated_at" = now(); RETURN NEW; END;`,
+	}
+	add := FunctionDefinition{
+		Name: "add",
+		Arguments: []FunctionArgumentDefinition{
+			{Name: "a", Type: types.Integer()},
+			{Name: "b", Type: types.Integer(), Default: "1"},
+		},
+		Returns:         types.Integer(),
+		Language:        "sql",
+		Body:            `SELECT a + b`,
+		Volatility:      FunctionImmutable,
+		SecurityDefiner: true,
+	}
+	archive := FunctionDefinition{
+		Name:      "archive",
+		Arguments: []FunctionArgumentDefinition{{Name: "before", Type: types.Timestamp(true)}},
+		Language:  "sql",
+		Body:      `DELETE FROM "posts" WHERE "created_at" < before`,
+	}
+
+	runBuilderTests(t, []builderTest{
+		{
+			name: "function",
+			build: func(builder Builder) {
+				builder.CreateFunction(touch)
+			},
+			want: []string{`CREATE FUNCTION "touch"() RETURNS TRIGGER LANGUAGE plpgsql AS $gomimi$BEGIN NEW."updated_at" = now(); RETURN NEW; END;$gomimi$;`},
+		},
+		{
+			name: "replaced function with arguments",
+			build: func(builder Builder) {
+				builder.InSchema("app").CreateOrReplaceFunction(add)
+			},
+			want: []string{`CREATE OR REPLACE FUNCTION "app"."add"("a" INTEGER,"b" INTEGER DEFAULT 1) RETURNS INTEGER LANGUAGE sql IMMUTABLE SECURITY DEFINER AS $gomimi$SELECT a + b$gomimi$;`},
+		},
+		{
+			name: "dropped function",
+			build: func(builder Builder) {
+				builder.DropFunction("add", types.Integer(), types.Integer())
+				builder.DropFunction("touch")
+			},
+			want: []string{`DROP FUNCTION IF EXISTS "add"(INTEGER,INTEGER);`, `DROP FUNCTION IF EXISTS "touch"();`},
+		},
+		{
+			name: "procedure",
+			build: func(builder Builder) {
+				builder.CreateProcedure(archive)
+				builder.CreateOrReplaceProcedure(archive)
+				builder.DropProcedure("archive", types.Timestamp(true))
+			},
+			want: []string{
+				`CREATE PROCEDURE "archive"("before" TIMESTAMPTZ) LANGUAGE sql AS $gomimi$DELETE FROM "posts" WHERE "created_at" < before$gomimi$;`,
+				`CREATE OR REPLACE PROCEDURE "archive"("before" TIMESTAMPTZ) LANGUAGE sql AS $gomimi$DELETE FROM "posts" WHERE "created_at" < before$gomimi$;`,
+				`DROP PROCEDURE IF EXISTS "archive"(TIMESTAMPTZ);`,
+			},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLTriggers(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{
+			name: "trigger",
+			build: func(builder Builder) {
+				builder.AlterTable("posts").CreateTrigger(TriggerDefinition{
+					Name:         "posts_touch",
+					Timing:       TriggerBefore,
+					Events:       []TriggerEvent{TriggerInsert, TriggerUpdate},
+					ForEachRow:   true,
+					FunctionName: "touch",
+				})
+			},
+			want: []string{`CREATE TRIGGER "posts_touch" BEFORE INSERT OR UPDATE ON "posts" FOR EACH ROW EXECUTE FUNCTION "touch"();`},
+		},
+		{
+			name: "trigger on columns with a condition and arguments",
+			build: func(builder Builder) {
+				builder.AlterTable("posts").CreateTrigger(TriggerDefinition{
+					Name:              "posts_audit",
+					Timing:            TriggerAfter,
+					Events:            []TriggerEvent{TriggerUpdate, TriggerDelete},
+					UpdateColumnNames: []string{"title", "body"},
+					ForEachRow:        true,
+					WhenCondition:     `OLD."title" IS DISTINCT FROM NEW."title"`,
+					FunctionName:      "audit",
+					FunctionArguments: []string{"posts"},
+				})
+			},
+			want: []string{`CREATE TRIGGER "posts_audit" AFTER UPDATE OF "title","body" OR DELETE ON "posts" FOR EACH ROW WHEN (OLD."title" IS DISTINCT FROM NEW."title") EXECUTE FUNCTION "audit"('posts');`},
+		},
+		{
+			name: "statement trigger",
+			build: func(builder Builder) {
+				builder.AlterTable("posts").CreateTrigger(TriggerDefinition{Name: "posts_truncate", Timing: TriggerAfter, Events: []TriggerEvent{TriggerTruncate}, FunctionName: "audit_truncate"})
+			},
+			want: []string{`CREATE TRIGGER "posts_truncate" AFTER TRUNCATE ON "posts" FOR EACH STATEMENT EXECUTE FUNCTION "audit_truncate"();`},
+		},
+		{
+			name: "dropped trigger",
+			build: func(builder Builder) {
+				builder.InSchema("app").AlterTable("posts").DropTrigger("posts_touch")
+			},
+			want: []string{`DROP TRIGGER IF EXISTS "posts_touch" ON "app"."posts";`},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLSchemasAndExtensions(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{
+			name: "schema",
+			build: func(builder Builder) {
+				builder.CreateSchema("app")
+				builder.DropSchema("app", false)
+				builder.DropSchema("app", true)
+			},
+			want: []string{
+				`CREATE SCHEMA IF NOT EXISTS "app";`,
+				`DROP SCHEMA IF EXISTS "app";`,
+				`DROP SCHEMA IF EXISTS "app" CASCADE;`,
+			},
+		},
+		{
+			name: "extension",
+			build: func(builder Builder) {
+				builder.CreateExtension(ExtensionDefinition{Name: "pg_trgm"})
+				builder.CreateExtension(ExtensionDefinition{Name: "postgis", Version: "3.4.0", SchemaName: "extensions", Cascade: true})
+			},
+			want: []string{`CREATE EXTENSION IF NOT EXISTS "pg_trgm";`, `CREATE EXTENSION IF NOT EXISTS "postgis" SCHEMA "extensions" VERSION '3.4.0' CASCADE;`},
+		},
+		{
+			name: "dropped extension",
+			build: func(builder Builder) {
+				builder.DropExtension("pg_trgm", false)
+				builder.DropExtension("postgis", true)
+			},
+			want: []string{`DROP EXTENSION IF EXISTS "pg_trgm";`, `DROP EXTENSION IF EXISTS "postgis" CASCADE;`},
+		},
+		{
+			name: "table in a schema",
+			build: func(builder Builder) {
+				builder.InSchema("app").CreateTable("users", []ColumnDefinition{{Name: "id", Type: types.BigInt(), PrimaryKey: true}}, nil)
+				builder.InSchema("app").AlterTable("users").Rename("accounts")
+				builder.InSchema("app").DropTable("accounts")
+			},
+			want: []string{
+				`CREATE TABLE IF NOT EXISTS "app"."users" ("id" BIGINT NOT NULL PRIMARY KEY);`,
+				`ALTER TABLE IF EXISTS "app"."users" RENAME TO "accounts";`,
+				`DROP TABLE IF EXISTS "app"."accounts";`,
+			},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLPartitions(t *testing.T) {
+	eventColumns := []ColumnDefinition{
+		{Name: "id", Type: types.BigInt()},
+		{Name: "region", Type: types.Text()},
+		{Name: "created_at", Type: types.Timestamp(true)},
+	}
+
+	runBuilderTests(t, []builderTest{
+		{
+			name: "partitioned table",
+			build: func(builder Builder) {
+				builder.CreateTable("events", eventColumns, nil, PartitionBy(PartitionRange, "created_at"))
+			},
+			want: []string{`CREATE TABLE IF NOT EXISTS "events" ("id" BIGINT NOT NULL,"region" TEXT NOT NULL,"created_at" TIMESTAMPTZ NOT NULL) PARTITION BY RANGE ("created_at");`},
+		},
+		{
+			name: "range, list, hash and default partitions",
+			build: func(builder Builder) {
+				builder.CreatePartitionOf("events_2024", "events", PartitionBounds{From: []string{"'2024-01-01'"}, To: []string{"'2025-01-01'"}})
+				builder.CreatePartitionOf("events_eu", "events", PartitionBounds{In: []string{"'de'", "'fr'"}})
+				builder.CreatePartitionOf("events_0", "events", PartitionBounds{Modulus: 4, Remainder: 0})
+				builder.CreatePartitionOf("events_default", "events", PartitionBounds{Default: true})
+			},
+			want: []string{
+				`CREATE TABLE IF NOT EXISTS "events_2024" PARTITION OF "events" FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');`,
+				`CREATE TABLE IF NOT EXISTS "events_eu" PARTITION OF "events" FOR VALUES IN ('de','fr');`,
+				`CREATE TABLE IF NOT EXISTS "events_0" PARTITION OF "events" FOR VALUES WITH (MODULUS 4, REMAINDER 0);`,
+				`CREATE TABLE IF NOT EXISTS "events_default" PARTITION OF "events" DEFAULT;`,
+			},
+		},
+		{
+			name: "attached and detached partitions",
+			build: func(builder Builder) {
+				builder.InSchema("app").AlterTable("events").AttachPartition("events_2023", PartitionBounds{From: []string{"MINVALUE"}, To: []string{"'2024-01-01'"}})
+				builder.InSchema("app").AlterTable("events").DetachPartition("events_2023", false)
+			},
+			want: []string{`ALTER TABLE IF EXISTS "app"."events" ATTACH PARTITION "app"."events_2023" FOR VALUES FROM (MINVALUE) TO ('2024-01-01');`, `ALTER TABLE IF EXISTS "app"."events" DETACH PARTITION "app"."events_2023";`},
+		},
+		{
+			name: "partition detached concurrently",
+			build: func(builder Builder) {
+				builder.AlterTable("events").DetachPartition("events_2023", true)
+			},
+			deferred: []string{`ALTER TABLE IF EXISTS "events" DETACH PARTITION "events_2023" CONCURRENTLY;`},
+		},
+		{
+			name: "monthly time partitions",
+			build: func(builder Builder) {
+				CreateTimePartitions(builder, "events", time.Date(2024, time.November, 15, 12, 0, 0, 0, time.UTC), PartitionMonthly, 3)
+			},
+			want: []string{
+				`CREATE TABLE IF NOT EXISTS "events_2024_11" PARTITION OF "events" FOR VALUES FROM ('2024-11-01 00:00:00+00:00') TO ('2024-12-01 00:00:00+00:00');`,
+				`CREATE TABLE IF NOT EXISTS "events_2024_12" PARTITION OF "events" FOR VALUES FROM ('2024-12-01 00:00:00+00:00') TO ('2025-01-01 00:00:00+00:00');`,
+				`CREATE TABLE IF NOT EXISTS "events_2025_01" PARTITION OF "events" FOR VALUES FROM ('2025-01-01 00:00:00+00:00') TO ('2025-02-01 00:00:00+00:00');`,
+			},
+		},
+	})
+}
+
+func TestTimePartitions(t *testing.T) {
+	start := time.Date(2024, time.February, 28, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		interval PartitionInterval
+		want     []string
+	}{
+		{PartitionDaily, []string{"events_2024_02_28", "events_2024_02_29", "events_2024_03_01"}},
+		// the 28th of february 2024 is a wednesday
+		{PartitionWeekly, []string{"events_2024_02_26", "events_2024_03_04", "events_2024_03_11"}},
+		{PartitionMonthly, []string{"events_2024_02", "events_2024_03", "events_2024_04"}},
+		{PartitionYearly, []string{"events_2024", "events_2025", "events_2026"}},
+	}
+
+	for _, test := range tests {
+		partitions := TimePartitions("events", start, test.interval, len(test.want))
+		if len(partitions) != len(test.want) {
+			t.Fatalf("partitions = %+v, want %v", partitions, len(test.want))
+		}
+		for index, partition := range partitions {
+			if partition.Name != test.want[index] {
+				t.Errorf("partitions[%d].Name = %v, want %v", index, partition.Name, test.want[index])
+			}
+			// every partition starts where the previous one ended
+			if index > 0 && !reflect.DeepEqual(partition.Bounds.From, partitions[index-1].Bounds.To) {
+				t.Errorf("partitions[%d].Bounds.From = %v, want %v", index, partition.Bounds.From, partitions[index-1].Bounds.To)
+			}
+		}
+	}
+}
+
+func TestBuilderPostgreSQLTableOptions(t *testing.T) {
+	columns := []ColumnDefinition{{Name: "id", Type: types.BigInt()}}
+
+	runBuilderTests(t, []builderTest{
+		{
+			name: "temporary and unlogged tables",
+			build: func(builder Builder) {
+				builder.CreateTable("scratch", columns, nil, Temporary())
+				builder.CreateTable("cache", columns, nil, Unlogged())
+			},
+			want: []string{`CREATE TEMPORARY TABLE IF NOT EXISTS "scratch" ("id" BIGINT NOT NULL);`, `CREATE UNLOGGED TABLE IF NOT EXISTS "cache" ("id" BIGINT NOT NULL);`},
+		},
+		{
+			name: "inherits, storage parameters and tablespace",
+			build: func(builder Builder) {
+				builder.CreateTable("archived_users", columns, nil, Inherits("users"), WithStorageParameter("fillfactor", "70"), InTablespace("archive"))
+			},
+			want: []string{`CREATE TABLE IF NOT EXISTS "archived_users" ("id" BIGINT NOT NULL) INHERITS ("users") WITH (fillfactor=70) TABLESPACE "archive";`},
+		},
+		{
+			name: "comments",
+			build: func(builder Builder) {
+				builder.CreateTable("users", []ColumnDefinition{{Name: "id", Type: types.BigInt(), Comment: "the user's id"}}, nil)
+				builder.InSchema("app").AlterTable("users").SetComment("registered users").SetColumnComment("id", "")
+			},
+			want: []string{
+				`CREATE TABLE IF NOT EXISTS "users" ("id" BIGINT NOT NULL);`,
+				`COMMENT ON COLUMN "users"."id" IS 'the user''s id';`,
+				`COMMENT ON TABLE "app"."users" IS 'registered users';`,
+				`COMMENT ON COLUMN "app"."users"."id" IS NULL;`,
+			},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLColumnOptions(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{
+			name: "generated column, collation and identity",
+			build: func(builder Builder) {
+				builder.CreateTable("people", []ColumnDefinition{
+					{Name: "id", Type: types.BigInt(), AutoIncrement: true, IdentityByDefault: true, IdentityStart: 100, IdentityIncrement: 2},
+					{Name: "name", Type: types.Text(), Collation: "C"},
+					{Name: "name_length", Type: types.Integer(), GeneratedExpression: `length("name")`},
+				}, nil)
+			},
+			want: []string{`CREATE TABLE IF NOT EXISTS "people" ("id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY (START WITH 100 INCREMENT BY 2),"name" TEXT COLLATE "C" NOT NULL,"name_length" INTEGER NOT NULL GENERATED ALWAYS AS (length("name")) STORED);`},
+		},
+		{
+			name: "storage and compression",
+			build: func(builder Builder) {
+				builder.AlterTable("documents").AddColumn(ColumnDefinition{Name: "body", Type: types.Text(), Nullable: true, Storage: ColumnStorageExternal, Compression: "lz4"})
+			},
+			want: []string{
+				`ALTER TABLE IF EXISTS "documents" ADD COLUMN IF NOT EXISTS "body" TEXT NULL;`,
+				`ALTER TABLE IF EXISTS "documents" ALTER COLUMN "body" SET STORAGE EXTERNAL;`,
+				`ALTER TABLE IF EXISTS "documents" ALTER COLUMN "body" SET COMPRESSION lz4;`,
+			},
+		},
+		{
+			name: "altered column options",
+			build: func(builder Builder) {
+				builder.AlterTable("people").AlterColumn("name", func(alterColumnBuilder AlterColumnBuilder) {
+					alterColumnBuilder.
+						SetCollation(types.Text(), "und-x-icu").
+						SetStorage(ColumnStorageMain).
+						SetCompression("pglz").
+						SetStatistics(500)
+				})
+				builder.AlterTable("people").AlterColumn("name_length", func(alterColumnBuilder AlterColumnBuilder) {
+					alterColumnBuilder.DropExpression()
+				})
+			},
+			want: []string{
+				`ALTER TABLE IF EXISTS "people" ALTER COLUMN "name" TYPE TEXT COLLATE "und-x-icu";`,
+				`ALTER TABLE IF EXISTS "people" ALTER COLUMN "name" SET STORAGE MAIN;`,
+				`ALTER TABLE IF EXISTS "people" ALTER COLUMN "name" SET COMPRESSION pglz;`,
+				`ALTER TABLE IF EXISTS "people" ALTER COLUMN "name" SET STATISTICS 500;`,
+				`ALTER TABLE IF EXISTS "people" ALTER COLUMN "name_length" DROP EXPRESSION IF EXISTS;`,
+			},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLConstraints(t *testing.T) {
+	noOverlap := ConstraintDefinition{
+		Name:            "reservations_no_overlap",
+		Type:            ConstraintExclusion,
+		ExclusionMethod: IndexGiST,
+		ExclusionElements: []ExclusionElementDefinition{
+			{ColumnName: "room_id", Operator: "="},
+			{Expression: `tstzrange("starts_at", "ends_at")`, Operator: "&&"},
+		},
+		ExclusionWhere: `NOT "cancelled"`,
+	}
+
+	runBuilderTests(t, []builderTest{
+		{
+			name: "exclusion constraint",
+			build: func(builder Builder) {
+				builder.CreateTable("reservations", []ColumnDefinition{{Name: "room_id", Type: types.BigInt()}}, []ConstraintDefinition{noOverlap})
+			},
+			want: []string{`CREATE TABLE IF NOT EXISTS "reservations" ("room_id" BIGINT NOT NULL,CONSTRAINT "reservations_no_overlap" EXCLUDE USING gist ("room_id" WITH =,(tstzrange("starts_at", "ends_at")) WITH &&) WHERE (NOT "cancelled"));`},
+		},
+		{
+			name: "not valid constraint",
+			build: func(builder Builder) {
+				builder.AlterTable("posts").AddConstraint(ConstraintDefinition{Name: "posts_title_check", Type: ConstraintCheck, CheckExpression: `"title" <> ''`, NotValid: true})
+				builder.InSchema("app").AlterTable("posts").AddConstraint(ConstraintDefinition{Name: "posts_user_id_fkey", Type: ConstraintForeignKey, ColumnNames: []string{"user_id"}, ReferenceTableName: "users", ReferenceColumnNames: []string{"id"}, NotValid: true})
+			},
+			want: []string{`ALTER TABLE IF EXISTS "posts" ADD CONSTRAINT "posts_title_check" CHECK ("title" <> '') NOT VALID;`, `ALTER TABLE IF EXISTS "app"."posts" ADD CONSTRAINT "posts_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "app"."users" ("id") NOT VALID;`},
+		},
+		{
+			name: "validated, renamed and dropped constraint",
+			build: func(builder Builder) {
+				builder.AlterTable("posts").
+					ValidateConstraint("posts_title_check").
+					RenameConstraint("posts_title_check", "posts_title_not_empty").
+					DropConstraint("posts_title_not_empty")
+			},
+			want: []string{
+				`ALTER TABLE IF EXISTS "posts" VALIDATE CONSTRAINT "posts_title_check";`,
+				`ALTER TABLE IF EXISTS "posts" RENAME CONSTRAINT "posts_title_check" TO "posts_title_not_empty";`,
+				`ALTER TABLE IF EXISTS "posts" DROP CONSTRAINT IF EXISTS "posts_title_not_empty";`,
+			},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLRolesAndGrants(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{
+			name: "role",
+			build: func(builder Builder) {
+				builder.CreateRole(RoleDefinition{Name: "readers"})
+				builder.CreateRole(RoleDefinition{
+					Name:            "app",
+					Login:           true,
+					Password:        "it's secret",
+					CreateDatabase:  true,
+					ConnectionLimit: 10,
+					ValidUntil:      "2030-01-01",
+					InRoleNames:     []string{"readers"},
+				})
+			},
+			want: []string{`CREATE ROLE "readers" WITH NOLOGIN NOSUPERUSER NOCREATEDB NOCREATEROLE NOBYPASSRLS;`, `CREATE ROLE "app" WITH LOGIN NOSUPERUSER CREATEDB NOCREATEROLE NOBYPASSRLS CONNECTION LIMIT 10 PASSWORD 'it''s secret' VALID UNTIL '2030-01-01' IN ROLE "readers";`},
+		},
+		{
+			name: "altered and dropped role",
+			build: func(builder Builder) {
+				builder.AlterRole(RoleAlteration{Name: "app", Login: RoleAttributeDisabled, BypassRLS: RoleAttributeEnabled, Password: "new secret"})
+				builder.DropRole("app")
+			},
+			want: []string{`ALTER ROLE "app" WITH NOLOGIN BYPASSRLS PASSWORD 'new secret';`, `DROP ROLE IF EXISTS "app";`},
+		},
+		{
+			name: "grants",
+			build: func(builder Builder) {
+				builder.Grant(GrantDefinition{Privileges: []string{"SELECT", "INSERT"}, ObjectType: GrantTable, ObjectNames: []string{"users", "posts"}, RoleNames: []string{"app"}})
+				builder.InSchema("app").Grant(GrantDefinition{Privileges: []string{"USAGE"}, ObjectType: GrantSequence, ObjectNames: []string{"order_number"}, RoleNames: []string{"app"}, WithGrantOption: true})
+				builder.Grant(GrantDefinition{Privileges: []string{"USAGE"}, ObjectType: GrantSchema, ObjectNames: []string{"app"}, RoleNames: []string{"readers"}})
+				builder.Grant(GrantDefinition{Privileges: []string{"EXECUTE"}, ObjectType: GrantFunction, ObjectNames: []string{"touch"}, RoleNames: []string{"app"}})
+			},
+			want: []string{
+				`GRANT SELECT,INSERT ON TABLE "users","posts" TO "app";`,
+				`GRANT USAGE ON SEQUENCE "app"."order_number" TO "app" WITH GRANT OPTION;`,
+				`GRANT USAGE ON SCHEMA "app" TO "readers";`,
+				`GRANT EXECUTE ON FUNCTION "touch" TO "app";`,
+			},
+		},
+		{
+			name: "revoke",
+			build: func(builder Builder) {
+				builder.Revoke(GrantDefinition{Privileges: []string{"SELECT"}, ObjectType: GrantTable, ObjectNames: []string{"users"}, RoleNames: []string{"app"}})
+				builder.Revoke(GrantDefinition{Privileges: []string{"USAGE"}, ObjectType: GrantSchema, ObjectNames: []string{"app"}, RoleNames: []string{"readers"}, WithGrantOption: true})
+			},
+			want: []string{`REVOKE SELECT ON TABLE "users" FROM "app";`, `REVOKE GRANT OPTION FOR USAGE ON SCHEMA "app" FROM "readers";`},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLRowLevelSecurity(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{
+			name: "row level security",
+			build: func(builder Builder) {
+				builder.InSchema("app").AlterTable("posts").
+					EnableRowLevelSecurity().
+					ForceRowLevelSecurity().
+					NoForceRowLevelSecurity().
+					DisableRowLevelSecurity()
+			},
+			want: []string{
+				`ALTER TABLE IF EXISTS "app"."posts" ENABLE ROW LEVEL SECURITY;`,
+				`ALTER TABLE IF EXISTS "app"."posts" FORCE ROW LEVEL SECURITY;`,
+				`ALTER TABLE IF EXISTS "app"."posts" NO FORCE ROW LEVEL SECURITY;`,
+				`ALTER TABLE IF EXISTS "app"."posts" DISABLE ROW LEVEL SECURITY;`,
+			},
+		},
+		{
+			name: "policies",
+			build: func(builder Builder) {
+				builder.AlterTable("posts").CreatePolicy(PolicyDefinition{Name: "posts_all", UsingExpression: "true"})
+				builder.AlterTable("posts").CreatePolicy(PolicyDefinition{
+					Name:                "posts_owner",
+					Restrictive:         true,
+					Command:             PolicyUpdate,
+					RoleNames:           []string{"app", "readers"},
+					UsingExpression:     `"user_id" = current_setting('app.user_id')::bigint`,
+					WithCheckExpression: `"user_id" = current_setting('app.user_id')::bigint`,
+				})
+				builder.AlterTable("posts").DropPolicy("posts_owner")
+			},
+			want: []string{
+				`CREATE POLICY "posts_all" ON "posts" USING (true);`,
+				`CREATE POLICY "posts_owner" ON "posts" AS RESTRICTIVE FOR UPDATE TO "app","readers" USING ("user_id" = current_setting('app.user_id')::bigint) WITH CHECK ("user_id" = current_setting('app.user_id')::bigint);`,
+				`DROP POLICY IF EXISTS "posts_owner" ON "posts";`,
+			},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLData(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{
+			name: "insert",
+			build: func(builder Builder) {
+				builder.Insert("roles", []Row{{"name": "admin", "level": 1}, {"name": "member", "level": 0}})
+			},
+			want: []string{`INSERT INTO "roles" ("level","name") VALUES ($1,$2),($3,$4);`},
+		},
+		{
+			name: "update and delete",
+			build: func(builder Builder) {
+				builder.InSchema("app").Update("users", Row{"verified": true}, `"email" = $1`, "a@example.com")
+				builder.Delete("sessions", `"expires_at" < now()`)
+			},
+			want: []string{`UPDATE "app"."users" SET "verified" = $2 WHERE "email" = $1;`, `DELETE FROM "sessions" WHERE "expires_at" < now();`},
+		},
+		{
+			name: "batched backfill",
+			build: func(builder Builder) {
+				builder.BatchBackfill(BackfillDefinition{
+					TableName:            "users",
+					PrimaryKeyColumnName: "id",
+					Set:                  map[string]string{"name": `lower("name")`},
+					Where:                `"name" <> lower("name")`,
+					BatchSize:            500,
+				})
+			},
+			deferred: []string{`WITH "batch" AS (SELECT "id" FROM "users" WHERE ("name" <> lower("name")) ORDER BY "id" LIMIT 500), "updated" AS (UPDATE "users" AS "target" SET "name" = lower("name") FROM "batch" WHERE "target"."id" = "batch"."id" RETURNING "target"."id") SELECT max("id") FROM "updated";`},
+		},
+	})
+}
+
+func TestBuilderPostgreSQLDataArguments(t *testing.T) {
+	builder := NewBuilderPostgreSQL()
+	builder.Insert("roles", []Row{{"name": "admin", "level": 1}, {"name": "member", "level": 0}})
+	builder.Update("users", Row{"verified": true}, `"email" = $1`, "a@example.com")
+
+	want := [][]any{{1, "admin", 0, "member"}, {"a@example.com", true}}
+	for index, statement := range builder.Statements() {
+		if !reflect.DeepEqual(statement.Arguments, want[index]) {
+			t.Errorf("statements[%d].Arguments = %v, want %v", index, statement.Arguments, want[index])
+		}
+	}
+}
+
+func TestBuilderPostgreSQLTimeouts(t *testing.T) {
+	timeouts := Timeouts{LockTimeout: 5 * time.Second, StatementTimeout: time.Minute}
+
+	runBuilderTests(t, []builderTest{
+		{
+			name: "local timeouts",
+			build: func(builder Builder) {
+				builder.SetTimeouts(timeouts, true)
+			},
+			want: []string{`SET LOCAL lock_timeout = '5000ms';`, `SET LOCAL statement_timeout = '60000ms';`},
+		},
+		{
+			name: "session timeouts",
+			build: func(builder Builder) {
+				builder.SetTimeouts(Timeouts{LockTimeout: 500 * time.Millisecond}, false)
+			},
+			want: []string{`SET lock_timeout = '500ms';`},
+		},
+		{
+			name: "deferred statements with local timeouts",
+			build: func(builder Builder) {
+				builder.SetTimeouts(timeouts, true)
+				builder.AlterTable("users").AddIndex(IndexDefinition{Name: "users_email_idx", ColumnNames: []string{"email"}, Concurrently: true})
+			},
+			want: []string{`SET LOCAL lock_timeout = '5000ms';`, `SET LOCAL statement_timeout = '60000ms';`},
+			deferred: []string{
+				`SET lock_timeout = '5000ms';`,
+				`SET statement_timeout = '60000ms';`,
+				`CREATE INDEX CONCURRENTLY IF NOT EXISTS "users_email_idx" ON "users" ("email");`,
+				`RESET lock_timeout;`,
+				`RESET statement_timeout;`,
+			},
+		},
+	})
+}
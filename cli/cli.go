@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ItsMalma/gomimi"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(db *sql.DB, runner gomimi.Runner, migrations ...gomimi.Migration) *cobra.Command {
+	command := &cobra.Command{
+		Use:          "gomimi",
+		Short:        "Run and inspect database migrations",
+		SilenceUsage: true,
+	}
+
+	command.AddCommand(
+		newMigrateCommand(db, runner, migrations),
+		newLintCommand(migrations),
+		newStatusCommand(runner, migrations),
+		newForceCommand(runner),
+		newMarkAppliedCommand(runner, migrations),
+		newMarkUnappliedCommand(runner, migrations),
+		newBaselineCommand(db, runner, migrations),
+		newSquashCommand(migrations),
+	)
+
+	return command
+}
+
+// the runner reports failures by panicking,
+// the commands turn them back into errors for cobra
+func recoverError(err *error) {
+	if recovered := recover(); recovered != nil {
+		if recoveredErr, ok := recovered.(error); ok {
+			*err = recoveredErr
+		} else {
+			*err = fmt.Errorf("%v", recovered)
+		}
+	}
+}
+
+func newMigrateCommand(db *sql.DB, runner gomimi.Runner, migrations []gomimi.Migration) *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Run every pending migration",
+		Args:  cobra.NoArgs,
+		RunE: func(command *cobra.Command, args []string) (err error) {
+			defer recoverError(&err)
+			runner.RunMigrationContext(command.Context(), db, migrations...)
+			return nil
+		},
+	}
+}
+
+func parseSeverity(name string) (gomimi.Severity, error) {
+	switch name {
+	case "info":
+		return gomimi.SeverityInfo, nil
+	case "warning":
+		return gomimi.SeverityWarning, nil
+	case "error":
+		return gomimi.SeverityError, nil
+	default:
+		return 0, fmt.Errorf(`unknown severity "%v", expected info, warning or error`, name)
+	}
+}
+
+func newLintCommand(migrations []gomimi.Migration) *cobra.Command {
+	var failOn string
+
+	command := &cobra.Command{
+		Use:   "lint",
+		Short: "Report dangerous operations in migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(command *cobra.Command, args []string) error {
+			failSeverity, err := parseSeverity(failOn)
+			if err != nil {
+				return err
+			}
+
+			findings, err := gomimi.NewLinter().Lint(migrations...)
+			if err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, finding := range findings {
+				command.Println(finding.String())
+				if finding.Severity >= failSeverity {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%v finding(s) at or above %v", failed, failSeverity)
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&failOn, "fail-on", "error", "lowest severity that fails the command (info, warning or error)")
+
+	return command
+}
+
+func newStatusCommand(runner gomimi.Runner, migrations []gomimi.Migration) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "List applied, pending and missing migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(command *cobra.Command, args []string) (err error) {
+			defer recoverError(&err)
+
+			report := runner.Status(migrations...)
+
+			writer := tabwriter.NewWriter(command.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(writer, "NAME\tSTATE\tAPPLIED AT\tDIRTY")
+			for _, status := range report.Migrations {
+				appliedAt := "-"
+				if !status.AppliedAt.IsZero() {
+					appliedAt = status.AppliedAt.Format(time.RFC3339)
+				}
+				fmt.Fprintf(writer, "%v\t%v\t%v\t%v\n", status.Name, status.State, appliedAt, status.Dirty)
+			}
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+
+			if report.Dirty {
+				return fmt.Errorf("database is dirty, a previous run failed part way")
+			}
+
+			return nil
+		},
+	}
+}
+
+func newForceCommand(runner gomimi.Runner) *cobra.Command {
+	return &cobra.Command{
+		Use:   "force [name]",
+		Short: "Make a migration current and clear the dirty state without running anything",
+		Long:  "Make a migration current and clear the dirty state without running anything, without a name no migration is applied.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(command *cobra.Command, args []string) (err error) {
+			defer recoverError(&err)
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			runner.Force(name)
+			return nil
+		},
+	}
+}
+
+func newMarkAppliedCommand(runner gomimi.Runner, migrations []gomimi.Migration) *cobra.Command {
+	return &cobra.Command{
+		Use:   "mark-applied name",
+		Short: "Record a migration as applied without running it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(command *cobra.Command, args []string) (err error) {
+			defer recoverError(&err)
+			runner.MarkApplied(args[0], migrations...)
+			return nil
+		},
+	}
+}
+
+func newMarkUnappliedCommand(runner gomimi.Runner, migrations []gomimi.Migration) *cobra.Command {
+	return &cobra.Command{
+		Use:   "mark-unapplied name",
+		Short: "Remove a migration from the history without running it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(command *cobra.Command, args []string) (err error) {
+			defer recoverError(&err)
+			runner.MarkUnapplied(args[0], migrations...)
+			return nil
+		},
+	}
+}
+
+func newBaselineCommand(db *sql.DB, runner gomimi.Runner, migrations []gomimi.Migration) *cobra.Command {
+	var verify bool
+
+	command := &cobra.Command{
+		Use:   "baseline name",
+		Short: "Record a migration and every migration before it as applied without running them",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(command *cobra.Command, args []string) (err error) {
+			defer recoverError(&err)
+
+			if !verify {
+				runner = runner.With(gomimi.WithSchemaVerifier(nil))
+			}
+			runner.BaselineContext(command.Context(), db, args[0], migrations...)
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&verify, "verify", true, "check that the tables and columns created by the migrations exist before recording them")
+
+	return command
+}
+
+func migrationRange(from string, to string, migrations []gomimi.Migration) ([]gomimi.Migration, error) {
+	fromIndex, toIndex := 0, len(migrations)-1
+	for index, migration := range migrations {
+		if migration.Name() == from {
+			fromIndex = index
+		}
+		if migration.Name() == to {
+			toIndex = index
+		}
+	}
+	if from != "" && (len(migrations) == 0 || migrations[fromIndex].Name() != from) {
+		return nil, fmt.Errorf(`migration "%v" does not exist`, from)
+	}
+	if to != "" && (len(migrations) == 0 || migrations[toIndex].Name() != to) {
+		return nil, fmt.Errorf(`migration "%v" does not exist`, to)
+	}
+	if fromIndex > toIndex {
+		return nil, fmt.Errorf(`migration "%v" comes after "%v"`, from, to)
+	}
+	return migrations[fromIndex : toIndex+1], nil
+}
+
+func newSquashCommand(migrations []gomimi.Migration) *cobra.Command {
+	var from, to, packageName, variableName, output string
+	var sqlOutput bool
+
+	command := &cobra.Command{
+		Use:   "squash name",
+		Short: "Replace a range of migrations with a single migration that creates their schema",
+		Long:  "Replace a range of migrations with a single migration that creates their schema, databases that applied the whole range treat the new migration as applied.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(command *cobra.Command, args []string) error {
+			squashMigrations, err := migrationRange(from, to, migrations)
+			if err != nil {
+				return err
+			}
+
+			squashed, err := gomimi.Squash(args[0], squashMigrations...)
+			if err != nil {
+				return err
+			}
+
+			var source []byte
+			if sqlOutput {
+				source = []byte(squashed.SQL(gomimi.NewBuilderPostgreSQL()) + "\n")
+			} else {
+				source, err = squashed.GoSource(packageName, variableName)
+				if err != nil {
+					return err
+				}
+			}
+
+			if output == "" {
+				_, err := command.OutOrStdout().Write(source)
+				return err
+			}
+			return os.WriteFile(output, source, 0644)
+		},
+	}
+
+	command.Flags().StringVar(&from, "from", "", "first migration to squash, defaults to the first migration")
+	command.Flags().StringVar(&to, "to", "", "last migration to squash, defaults to the last migration")
+	command.Flags().StringVar(&packageName, "package", "migrations", "package of the generated Go file")
+	command.Flags().StringVar(&variableName, "var", "Squashed", "variable that holds the squashed migration in the generated Go file")
+	command.Flags().StringVar(&output, "output", "", "file to write to, defaults to standard output")
+	command.Flags().BoolVar(&sqlOutput, "sql", false, "write PostgreSQL statements instead of a Go file")
+
+	return command
+}
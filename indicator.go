@@ -1,6 +1,28 @@
 package gomimi
 
+import "time"
+
 type Indicator interface {
 	Current() string
 	Change(newName string)
 }
+
+type AppliedMigration struct {
+	Name      string
+	AppliedAt time.Time
+	Dirty     bool
+}
+
+// HistoryIndicator is implemented by indicators
+// that remember when every migration was applied,
+// a migration marked in progress stays dirty until it is marked applied or unapplied.
+// MarkCurrent marks the migration applied and makes it current in a single step,
+// so a crash cannot leave one recorded without the other
+type HistoryIndicator interface {
+	Indicator
+	Applied() []AppliedMigration
+	MarkInProgress(name string)
+	MarkApplied(name string)
+	MarkCurrent(name string)
+	MarkUnapplied(name string)
+}
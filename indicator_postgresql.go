@@ -1,16 +1,19 @@
 package gomimi
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"time"
 )
 
 type indicatorPostgreSQL struct {
-	db *sql.DB
+	db     *sql.DB
+	logger Logger
 }
 
-func NewIndicatorPostgreSQL(db *sql.DB) Indicator {
-	return &indicatorPostgreSQL{db}
+func NewIndicatorPostgreSQL(db *sql.DB, options ...IndicatorOption) HistoryIndicator {
+	return &indicatorPostgreSQL{db: db, logger: newIndicatorOptions(options).logger}
 }
 
 func (indicator *indicatorPostgreSQL) IfTableExists() bool {
@@ -33,6 +36,7 @@ func (indicator *indicatorPostgreSQL) CreateMigrationTable() {
 	if err != nil {
 		panic(err)
 	}
+	indicator.logger.Info("migration table created", "table", "gomimi")
 }
 
 func (indicator *indicatorPostgreSQL) Current() string {
@@ -57,30 +61,139 @@ func (indicator *indicatorPostgreSQL) Current() string {
 	return currentName
 }
 
+// inTransaction runs the writes of the indicator in a single transaction,
+// so the history and the current migration never disagree
+func (indicator *indicatorPostgreSQL) inTransaction(write func(tx *sql.Tx) error) {
+	tx, err := indicator.db.Begin()
+	if err != nil {
+		panic(err)
+	}
+	if err := write(tx); err != nil {
+		tx.Rollback()
+		panic(err)
+	}
+	if err := tx.Commit(); err != nil {
+		panic(err)
+	}
+}
+
+func changePostgreSQL(executor Executor, newName string) error {
+	ctx := context.Background()
+
+	var id int64
+	if err := executor.QueryRowContext(ctx, `SELECT "id" FROM "gomimi" LIMIT 1;`).Scan(&id); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		_, err := executor.ExecContext(ctx, `INSERT INTO "gomimi" ("name") VALUES ($1);`, newName)
+		return err
+	}
+
+	_, err := executor.ExecContext(ctx, `UPDATE "gomimi" SET "name" = $1 WHERE "id" = $2;`, newName, id)
+	return err
+}
+
 func (indicator *indicatorPostgreSQL) Change(newName string) {
 	if !indicator.IfTableExists() {
 		indicator.CreateMigrationTable()
 	}
 
-	row := indicator.db.QueryRow(`SELECT "id" FROM "gomimi" LIMIT 1;`)
-	if err := row.Err(); err != nil {
+	if err := changePostgreSQL(indicator.db, newName); err != nil {
 		panic(err)
 	}
+	indicator.logger.Info("current migration changed", "migration", newName)
+}
 
-	var id int64
-	if err := row.Scan(&id); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			_, err := indicator.db.Exec(`INSERT INTO "gomimi" ("name") VALUES (?)`, newName)
-			if err != nil {
-				panic(err)
-			}
-		} else {
+func (indicator *indicatorPostgreSQL) CreateHistoryTable() {
+	_, err := indicator.db.Exec(`CREATE TABLE IF NOT EXISTS "gomimi_history" (
+		"name" TEXT PRIMARY KEY NOT NULL,
+		"applied_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+		"dirty" BOOLEAN NOT NULL DEFAULT FALSE
+	);`)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (indicator *indicatorPostgreSQL) Applied() []AppliedMigration {
+	row := indicator.db.QueryRow(`SELECT EXISTS (SELECT FROM "pg_tables" WHERE tablename = 'gomimi_history');`)
+	var exists bool
+	if err := row.Scan(&exists); err != nil {
+		panic(err)
+	}
+	if !exists {
+		return nil
+	}
+
+	rows, err := indicator.db.Query(`SELECT "name", "applied_at", "dirty" FROM "gomimi_history" ORDER BY "applied_at", "name";`)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+
+	appliedMigrations := []AppliedMigration{}
+	for rows.Next() {
+		var appliedMigration AppliedMigration
+		if err := rows.Scan(&appliedMigration.Name, &appliedMigration.AppliedAt, &appliedMigration.Dirty); err != nil {
 			panic(err)
 		}
-	} else {
-		_, err := indicator.db.Exec(`UPDATE FROM "gomimi" SET "name" = ? WHERE id = ?`, newName, id)
-		if err != nil {
-			panic(err)
+		appliedMigration.AppliedAt = appliedMigration.AppliedAt.In(time.UTC)
+		appliedMigrations = append(appliedMigrations, appliedMigration)
+	}
+	if err := rows.Err(); err != nil {
+		panic(err)
+	}
+
+	return appliedMigrations
+}
+
+func markAppliedPostgreSQL(executor Executor, name string) error {
+	_, err := executor.ExecContext(context.Background(), `INSERT INTO "gomimi_history" ("name") VALUES ($1)
+		ON CONFLICT ("name") DO UPDATE SET "applied_at" = now(), "dirty" = FALSE;`, name)
+	return err
+}
+
+func (indicator *indicatorPostgreSQL) MarkApplied(name string) {
+	indicator.CreateHistoryTable()
+
+	if err := markAppliedPostgreSQL(indicator.db, name); err != nil {
+		panic(err)
+	}
+	indicator.logger.Debug("migration marked applied", "migration", name)
+}
+
+func (indicator *indicatorPostgreSQL) MarkCurrent(name string) {
+	indicator.CreateHistoryTable()
+	if !indicator.IfTableExists() {
+		indicator.CreateMigrationTable()
+	}
+
+	indicator.inTransaction(func(tx *sql.Tx) error {
+		if err := markAppliedPostgreSQL(tx, name); err != nil {
+			return err
 		}
+		return changePostgreSQL(tx, name)
+	})
+	indicator.logger.Info("current migration changed", "migration", name)
+}
+
+func (indicator *indicatorPostgreSQL) MarkInProgress(name string) {
+	indicator.CreateHistoryTable()
+
+	_, err := indicator.db.Exec(`INSERT INTO "gomimi_history" ("name", "dirty") VALUES ($1, TRUE)
+		ON CONFLICT ("name") DO UPDATE SET "dirty" = TRUE;`, name)
+	if err != nil {
+		panic(err)
+	}
+	indicator.logger.Debug("migration marked in progress", "migration", name)
+}
+
+func (indicator *indicatorPostgreSQL) MarkUnapplied(name string) {
+	indicator.CreateHistoryTable()
+
+	_, err := indicator.db.Exec(`DELETE FROM "gomimi_history" WHERE "name" = $1;`, name)
+	if err != nil {
+		panic(err)
 	}
+	indicator.logger.Debug("migration marked unapplied", "migration", name)
 }
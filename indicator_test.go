@@ -0,0 +1,53 @@
+package gomimi
+
+import "time"
+
+// memoryIndicator keeps the current migration and the history in memory
+type memoryIndicator struct {
+	current string
+	history []AppliedMigration
+}
+
+func (indicator *memoryIndicator) Current() string {
+	return indicator.current
+}
+
+func (indicator *memoryIndicator) Change(newName string) {
+	indicator.current = newName
+}
+
+func (indicator *memoryIndicator) Applied() []AppliedMigration {
+	return append([]AppliedMigration{}, indicator.history...)
+}
+
+func (indicator *memoryIndicator) mark(name string, dirty bool) {
+	for index := range indicator.history {
+		if indicator.history[index].Name == name {
+			indicator.history[index].Dirty = dirty
+			return
+		}
+	}
+	indicator.history = append(indicator.history, AppliedMigration{Name: name, AppliedAt: time.Now().UTC(), Dirty: dirty})
+}
+
+func (indicator *memoryIndicator) MarkInProgress(name string) {
+	indicator.mark(name, true)
+}
+
+func (indicator *memoryIndicator) MarkApplied(name string) {
+	indicator.mark(name, false)
+}
+
+func (indicator *memoryIndicator) MarkCurrent(name string) {
+	indicator.mark(name, false)
+	indicator.current = name
+}
+
+func (indicator *memoryIndicator) MarkUnapplied(name string) {
+	for index := range indicator.history {
+		if indicator.history[index].Name == name {
+			indicator.history = append(indicator.history[:index], indicator.history[index+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package gomimi
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Severity uint8
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+type LintFinding struct {
+	RuleID        string
+	Severity      Severity
+	MigrationName string
+	Operation     Operation
+	Message       string
+}
+
+func (finding LintFinding) String() string {
+	return fmt.Sprintf(`%v: [%v] %v: %v`, finding.MigrationName, finding.RuleID, finding.Severity, finding.Message)
+}
+
+type LintRule struct {
+	ID       string
+	Severity Severity
+	Check    func(operation Operation, previousOperations []Operation) (message string, found bool)
+}
+
+// LintSuppression can be implemented by a migration
+// to silence the given rule ids after the operation has been reviewed
+type LintSuppression interface {
+	SuppressLint() []string
+}
+
+type Linter struct {
+	rules []LintRule
+}
+
+func NewLinter(rules ...LintRule) Linter {
+	if len(rules) == 0 {
+		rules = DefaultLintRules()
+	}
+	return Linter{rules}
+}
+
+func (linter Linter) Lint(migrations ...Migration) ([]LintFinding, error) {
+	findings := []LintFinding{}
+
+	for _, migration := range migrations {
+		// function migrations need a database connection,
+		// so only builder based migrations can be inspected
+		if _, ok := migration.(funcMigration); ok {
+			continue
+		}
+
+		recorder := NewRecorder()
+		if err := migration.Up(recorder); err != nil {
+			return nil, fmt.Errorf(`migration "%v": %w`, migration.Name(), err)
+		}
+
+		suppressedRuleIDs := map[string]bool{}
+		if suppression, ok := migrationValue(migration).(LintSuppression); ok {
+			for _, ruleID := range suppression.SuppressLint() {
+				suppressedRuleIDs[ruleID] = true
+			}
+		}
+
+		operations := recorder.Operations()
+		for index, operation := range operations {
+			for _, rule := range linter.rules {
+				if suppressedRuleIDs[rule.ID] {
+					continue
+				}
+				if message, found := rule.Check(operation, operations[:index]); found {
+					findings = append(findings, LintFinding{
+						RuleID:        rule.ID,
+						Severity:      rule.Severity,
+						MigrationName: migration.Name(),
+						Operation:     operation,
+						Message:       message,
+					})
+				}
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+func isCreatedTable(previousOperations []Operation, operation Operation) bool {
+	for _, previousOperation := range previousOperations {
+		if (previousOperation.Method == "CreateTable" || previousOperation.Method == "CreatePartitionOf") &&
+			previousOperation.SchemaName == operation.SchemaName &&
+			previousOperation.ObjectName == operation.ObjectName {
+			return true
+		}
+	}
+	return false
+}
+
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		{
+			ID:       "GM001",
+			Severity: SeverityError,
+			Check: func(operation Operation, previousOperations []Operation) (string, bool) {
+				if operation.Method != "AlterColumn.AlterType" && operation.Method != "AlterColumn.SetCollation" {
+					return "", false
+				}
+				if isCreatedTable(previousOperations, operation) {
+					return "", false
+				}
+				return fmt.Sprintf(
+					`changing the type of column "%v" on "%v" may rewrite the table while holding an ACCESS EXCLUSIVE lock`,
+					operation.ColumnName,
+					operation.ObjectName,
+				), true
+			},
+		},
+		{
+			ID:       "GM002",
+			Severity: SeverityError,
+			Check: func(operation Operation, previousOperations []Operation) (string, bool) {
+				if operation.Method != "AlterTable.AddColumn" {
+					return "", false
+				}
+				column := operation.Arguments[0].(ColumnDefinition)
+				if column.Nullable || column.Default != "" || column.AutoIncrement || column.GeneratedExpression != "" {
+					return "", false
+				}
+				if isCreatedTable(previousOperations, operation) {
+					return "", false
+				}
+				return fmt.Sprintf(
+					`adding NOT NULL column "%v" without a default to "%v" fails when the table has rows`,
+					column.Name,
+					operation.ObjectName,
+				), true
+			},
+		},
+		{
+			ID:       "GM003",
+			Severity: SeverityWarning,
+			Check: func(operation Operation, previousOperations []Operation) (string, bool) {
+				if operation.Method != "AlterColumn.DropNullable" || isCreatedTable(previousOperations, operation) {
+					return "", false
+				}
+				return fmt.Sprintf(
+					`setting NOT NULL on column "%v" of "%v" scans the whole table while holding an ACCESS EXCLUSIVE lock, `+
+						`consider a NOT VALID check constraint first`,
+					operation.ColumnName,
+					operation.ObjectName,
+				), true
+			},
+		},
+		{
+			ID:       "GM004",
+			Severity: SeverityError,
+			Check: func(operation Operation, previousOperations []Operation) (string, bool) {
+				if operation.Method != "AlterTable.AddIndex" || isCreatedTable(previousOperations, operation) {
+					return "", false
+				}
+				if index := operation.Arguments[0].(IndexDefinition); index.Concurrently {
+					return "", false
+				}
+				return fmt.Sprintf(`creating an index on "%v" without CONCURRENTLY blocks writes to the table`, operation.ObjectName), true
+			},
+		},
+		{
+			ID:       "GM005",
+			Severity: SeverityWarning,
+			Check: func(operation Operation, previousOperations []Operation) (string, bool) {
+				if operation.Method != "AlterTable.DropColumn" {
+					return "", false
+				}
+				return fmt.Sprintf(
+					`dropping column "%v" from "%v" breaks application code that still reads it`,
+					operation.Arguments[0],
+					operation.ObjectName,
+				), true
+			},
+		},
+		{
+			ID:       "GM006",
+			Severity: SeverityError,
+			Check: func(operation Operation, previousOperations []Operation) (string, bool) {
+				if operation.Method != "TruncateTable" {
+					return "", false
+				}
+				return fmt.Sprintf(`truncating "%v" deletes every row while holding an ACCESS EXCLUSIVE lock`, operation.ObjectName), true
+			},
+		},
+		{
+			ID:       "GM007",
+			Severity: SeverityWarning,
+			Check: func(operation Operation, previousOperations []Operation) (string, bool) {
+				if isCreatedTable(previousOperations, operation) {
+					return "", false
+				}
+				switch operation.Method {
+				case "AlterTable.AddConstraint":
+					constraint := operation.Arguments[0].(ConstraintDefinition)
+					if (constraint.Type != ConstraintForeignKey && constraint.Type != ConstraintCheck) || constraint.NotValid {
+						return "", false
+					}
+					return fmt.Sprintf(
+						`adding constraint "%v" to "%v" without NOT VALID scans the whole table while holding a lock, `+
+							`add it as NOT VALID and validate it in a later migration`,
+						constraint.Name,
+						operation.ObjectName,
+					), true
+				case "AlterTable.AddColumn":
+					column := operation.Arguments[0].(ColumnDefinition)
+					if !column.Reference {
+						return "", false
+					}
+					return fmt.Sprintf(
+						`adding column "%v" with an inline foreign key to "%v" validates every row while holding a lock`,
+						column.Name,
+						operation.ObjectName,
+					), true
+				default:
+					return "", false
+				}
+			},
+		},
+		{
+			ID:       "GM008",
+			Severity: SeverityWarning,
+			Check: func(operation Operation, previousOperations []Operation) (string, bool) {
+				if operation.Method != "DropTable" || isCreatedTable(previousOperations, operation) {
+					return "", false
+				}
+				return fmt.Sprintf(`dropping table "%v" permanently deletes its data`, operation.ObjectName), true
+			},
+		},
+	}
+}
+
+func FormatLintFindings(findings []LintFinding) string {
+	lines := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		lines = append(lines, finding.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,232 @@
+package gomimi
+
+import (
+	"testing"
+
+	"github.com/ItsMalma/gomimi/types"
+)
+
+func defaultLintRule(t *testing.T, id string) LintRule {
+	t.Helper()
+	for _, rule := range DefaultLintRules() {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	t.Fatalf("no default lint rule %v", id)
+	return LintRule{}
+}
+
+func TestDefaultLintRules(t *testing.T) {
+	usersTable := func(builder Builder) {
+		builder.CreateTable("users", []ColumnDefinition{{Name: "id", Type: types.BigInt(), PrimaryKey: true}, {Name: "email", Type: types.Text()}}, nil)
+	}
+
+	tests := []struct {
+		name   string
+		ruleID string
+		change func(builder Builder)
+		fires  bool
+	}{
+		{
+			name:   "column type change on an existing table",
+			ruleID: "GM001",
+			change: func(builder Builder) {
+				builder.AlterTable("users").AlterColumn("email", func(alterColumnBuilder AlterColumnBuilder) {
+					alterColumnBuilder.AlterType(types.Varchar(255))
+				})
+			},
+			fires: true,
+		},
+		{
+			name:   "column type change on a table created in the migration",
+			ruleID: "GM001",
+			change: func(builder Builder) {
+				usersTable(builder)
+				builder.AlterTable("users").AlterColumn("email", func(alterColumnBuilder AlterColumnBuilder) {
+					alterColumnBuilder.AlterType(types.Varchar(255))
+				})
+			},
+			fires: false,
+		},
+		{
+			name:   "NOT NULL column without a default",
+			ruleID: "GM002",
+			change: func(builder Builder) {
+				builder.AlterTable("users").AddColumn(ColumnDefinition{Name: "age", Type: types.Integer()})
+			},
+			fires: true,
+		},
+		{
+			name:   "NOT NULL column with a default",
+			ruleID: "GM002",
+			change: func(builder Builder) {
+				builder.AlterTable("users").AddColumn(ColumnDefinition{Name: "age", Type: types.Integer(), Default: "0"})
+			},
+			fires: false,
+		},
+		{
+			name:   "setting NOT NULL on an existing table",
+			ruleID: "GM003",
+			change: func(builder Builder) {
+				builder.AlterTable("users").AlterColumn("email", func(alterColumnBuilder AlterColumnBuilder) {
+					alterColumnBuilder.DropNullable()
+				})
+			},
+			fires: true,
+		},
+		{
+			name:   "setting NOT NULL on a table created in the migration",
+			ruleID: "GM003",
+			change: func(builder Builder) {
+				usersTable(builder)
+				builder.AlterTable("users").AlterColumn("email", func(alterColumnBuilder AlterColumnBuilder) {
+					alterColumnBuilder.DropNullable()
+				})
+			},
+			fires: false,
+		},
+		{
+			name:   "index without CONCURRENTLY",
+			ruleID: "GM004",
+			change: func(builder Builder) {
+				builder.AlterTable("users").AddIndex(IndexDefinition{Name: "users_email_idx", ColumnNames: []string{"email"}})
+			},
+			fires: true,
+		},
+		{
+			name:   "index with CONCURRENTLY",
+			ruleID: "GM004",
+			change: func(builder Builder) {
+				builder.AlterTable("users").AddIndex(IndexDefinition{Name: "users_email_idx", ColumnNames: []string{"email"}, Concurrently: true})
+			},
+			fires: false,
+		},
+		{
+			name:   "dropped column",
+			ruleID: "GM005",
+			change: func(builder Builder) {
+				builder.AlterTable("users").DropColumn("email")
+			},
+			fires: true,
+		},
+		{
+			name:   "renamed column",
+			ruleID: "GM005",
+			change: func(builder Builder) {
+				builder.AlterTable("users").RenameColumn("email", "mail")
+			},
+			fires: false,
+		},
+		{
+			name:   "truncated table",
+			ruleID: "GM006",
+			change: func(builder Builder) {
+				builder.TruncateTable("users")
+			},
+			fires: true,
+		},
+		{
+			name:   "deleted rows",
+			ruleID: "GM006",
+			change: func(builder Builder) {
+				builder.Delete("users", `"email" IS NULL`)
+			},
+			fires: false,
+		},
+		{
+			name:   "foreign key without NOT VALID",
+			ruleID: "GM007",
+			change: func(builder Builder) {
+				builder.AlterTable("posts").AddConstraint(ConstraintDefinition{
+					Name:                 "posts_user_fk",
+					Type:                 ConstraintForeignKey,
+					ColumnNames:          []string{"user_id"},
+					ReferenceTableName:   "users",
+					ReferenceColumnNames: []string{"id"},
+				})
+			},
+			fires: true,
+		},
+		{
+			name:   "inline foreign key on a new column",
+			ruleID: "GM007",
+			change: func(builder Builder) {
+				builder.AlterTable("posts").AddColumn(ColumnDefinition{
+					Name:                 "user_id",
+					Type:                 types.BigInt(),
+					Nullable:             true,
+					Reference:            true,
+					ReferenceTableName:   "users",
+					ReferenceColumnNames: []string{"id"},
+				})
+			},
+			fires: true,
+		},
+		{
+			name:   "foreign key with NOT VALID",
+			ruleID: "GM007",
+			change: func(builder Builder) {
+				builder.AlterTable("posts").AddConstraint(ConstraintDefinition{
+					Name:                 "posts_user_fk",
+					Type:                 ConstraintForeignKey,
+					ColumnNames:          []string{"user_id"},
+					ReferenceTableName:   "users",
+					ReferenceColumnNames: []string{"id"},
+					NotValid:             true,
+				})
+			},
+			fires: false,
+		},
+		{
+			name:   "dropped table",
+			ruleID: "GM008",
+			change: func(builder Builder) {
+				builder.DropTable("users")
+			},
+			fires: true,
+		},
+		{
+			name:   "dropped table created in the migration",
+			ruleID: "GM008",
+			change: func(builder Builder) {
+				usersTable(builder)
+				builder.DropTable("users")
+			},
+			fires: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ruleID+" "+test.name, func(t *testing.T) {
+			rule := defaultLintRule(t, test.ruleID)
+			findings, err := NewLinter(rule).Lint(newTestMigration("001", test.change))
+			if err != nil {
+				t.Fatalf("Lint() error = %v", err)
+			}
+			if fires := len(findings) > 0; fires != test.fires {
+				t.Fatalf("Lint() findings = %v, want fired %v", findings, test.fires)
+			}
+			for _, finding := range findings {
+				if finding.RuleID != test.ruleID || finding.Severity != rule.Severity || finding.MigrationName != "001" {
+					t.Errorf("Lint() finding = %+v", finding)
+				}
+			}
+		})
+	}
+}
+
+func TestLintSuppression(t *testing.T) {
+	dropColumn := func(builder Builder) {
+		builder.AlterTable("users").DropColumn("email")
+		builder.TruncateTable("users")
+	}
+
+	findings, err := NewLinter().Lint(Reversible(testMigration{name: "001", change: dropColumn, suppressedRuleIDs: []string{"GM005"}}))
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	if len(findings) != 1 || findings[0].RuleID != "GM006" {
+		t.Errorf("Lint() findings = %v, want only GM006", findings)
+	}
+}
@@ -0,0 +1,115 @@
+package gomimi
+
+import (
+	"context"
+	"time"
+)
+
+// Logger is satisfied by *slog.Logger,
+// args are alternating keys and values
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...any) {}
+
+func (nopLogger) Info(msg string, args ...any) {}
+
+func (nopLogger) Warn(msg string, args ...any) {}
+
+func (nopLogger) Error(msg string, args ...any) {}
+
+// WithLogger logs every run, migration and statement,
+// statements are logged at debug level
+func WithLogger(logger Logger) RunnerOption {
+	return func(runner *Runner) {
+		runner.logger = logger
+		runner.observers = append(runner.observers, loggingObserver{logger: logger})
+	}
+}
+
+func (runner Runner) log() Logger {
+	if runner.logger == nil {
+		return nopLogger{}
+	}
+	return runner.logger
+}
+
+func (runner Runner) logTimeouts(run migrationRun, timeouts Timeouts) {
+	if timeouts.LockTimeout > 0 {
+		runner.log().Debug("lock timeout set", "migration", run.name, "direction", run.direction.String(), "lock_timeout", timeouts.LockTimeout)
+	}
+}
+
+type loggingObserver struct {
+	NopObserver
+	logger Logger
+}
+
+func (observer loggingObserver) BeforeRun(ctx context.Context, migrations []Migration) context.Context {
+	observer.logger.Info("migration run started", "migrations", len(migrations))
+	return ctx
+}
+
+func (observer loggingObserver) BeforeMigration(ctx context.Context, name string, direction Direction) context.Context {
+	// the runner only runs a down migration to compensate for a failed up migration
+	if direction == DirectionDown {
+		observer.logger.Warn("compensating migration started", "migration", name, "direction", direction.String())
+	} else {
+		observer.logger.Info("migration started", "migration", name, "direction", direction.String())
+	}
+	return ctx
+}
+
+func (observer loggingObserver) AfterStatement(ctx context.Context, name string, direction Direction, statement Statement, duration time.Duration, err error) {
+	if err != nil {
+		observer.logger.Error("statement failed", "migration", name, "direction", direction.String(), "query", statement.Redacted(), "duration", duration, "error", err)
+		return
+	}
+	observer.logger.Debug("statement executed", "migration", name, "direction", direction.String(), "query", statement.Redacted(), "duration", duration)
+}
+
+func (observer loggingObserver) AfterMigration(ctx context.Context, name string, direction Direction, duration time.Duration, err error) {
+	if err != nil {
+		observer.logger.Error("migration failed", "migration", name, "direction", direction.String(), "duration", duration, "error", err)
+		return
+	}
+	observer.logger.Info("migration finished", "migration", name, "direction", direction.String(), "duration", duration)
+}
+
+func (observer loggingObserver) AfterRun(ctx context.Context, report RunReport) {
+	if report.Err != nil {
+		observer.logger.Error("migration run failed", "applied", len(report.Applied), "rolled_back", len(report.RolledBack), "failed", report.Failed, "duration", report.Duration, "error", report.Err)
+		return
+	}
+	observer.logger.Info("migration run finished", "applied", len(report.Applied), "rolled_back", len(report.RolledBack), "duration", report.Duration)
+}
+
+func (observer loggingObserver) OnRollback(ctx context.Context, name string, direction Direction, err error) {
+	observer.logger.Warn("transaction rolled back", "migration", name, "direction", direction.String(), "error", err)
+}
+
+type indicatorOptions struct {
+	logger Logger
+}
+
+type IndicatorOption func(options *indicatorOptions)
+
+func WithIndicatorLogger(logger Logger) IndicatorOption {
+	return func(options *indicatorOptions) {
+		options.logger = logger
+	}
+}
+
+func newIndicatorOptions(options []IndicatorOption) indicatorOptions {
+	indicatorOptions := indicatorOptions{logger: nopLogger{}}
+	for _, option := range options {
+		option(&indicatorOptions)
+	}
+	return indicatorOptions
+}
@@ -0,0 +1,179 @@
+package gomimi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type logEntry struct {
+	level   string
+	message string
+	args    []any
+}
+
+// String renders the entry like a text handler would,
+// so tests can look for a message and its attributes at once
+func (entry logEntry) String() string {
+	return fmt.Sprint(append([]any{entry.level, entry.message}, entry.args...)...)
+}
+
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (logger *recordingLogger) log(level string, message string, args []any) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.entries = append(logger.entries, logEntry{level, message, args})
+}
+
+func (logger *recordingLogger) Debug(msg string, args ...any) { logger.log("DEBUG", msg, args) }
+
+func (logger *recordingLogger) Info(msg string, args ...any) { logger.log("INFO", msg, args) }
+
+func (logger *recordingLogger) Warn(msg string, args ...any) { logger.log("WARN", msg, args) }
+
+func (logger *recordingLogger) Error(msg string, args ...any) { logger.log("ERROR", msg, args) }
+
+func TestStatementRedacted(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`CREATE ROLE "app" WITH LOGIN PASSWORD 'secret';`, `CREATE ROLE "app" WITH LOGIN PASSWORD '[REDACTED]';`},
+		{`ALTER ROLE "app" WITH password 'it''s secret' VALID UNTIL 'infinity';`, `ALTER ROLE "app" WITH PASSWORD '[REDACTED]' VALID UNTIL 'infinity';`},
+		{`CREATE TABLE "passwords" ("password" TEXT);`, `CREATE TABLE "passwords" ("password" TEXT);`},
+	}
+
+	for _, test := range tests {
+		if got := (Statement{Query: test.query}).Redacted(); got != test.want {
+			t.Errorf("Redacted() = %v, want %v", got, test.want)
+		}
+	}
+}
+
+func TestLoggingObserverRedactsPasswords(t *testing.T) {
+	logger := &recordingLogger{}
+	observer := loggingObserver{logger: logger}
+	statement := Statement{Query: `CREATE ROLE "app" WITH LOGIN PASSWORD 'secret';`}
+
+	observer.AfterStatement(context.Background(), "001", DirectionUp, statement, time.Millisecond, nil)
+	observer.AfterStatement(context.Background(), "001", DirectionUp, statement, time.Millisecond, errFakeStatement)
+
+	for _, entry := range logger.entries {
+		if strings.Contains(entry.String(), "secret") {
+			t.Errorf("%v logged the password", entry)
+		}
+	}
+}
+
+func TestRunnerLogsLocks(t *testing.T) {
+	failed := false
+	connector := &fakeConnector{fail: func(query string) bool {
+		if strings.HasPrefix(query, "CREATE TABLE") && !failed {
+			failed = true
+			return true
+		}
+		return false
+	}}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	logger := &recordingLogger{}
+	runner := NewRunner(&memoryIndicator{}, NewBuilderPostgreSQL(),
+		WithLogger(logger),
+		WithTimeouts(Timeouts{LockTimeout: time.Second}),
+		WithRetryPolicy(RetryPolicy{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+			Retryable: func(err error) bool {
+				return errors.Is(err, errFakeStatement)
+			},
+		}),
+	)
+
+	if err := runMigrations(runner, db, createTableMigration("001", "users")); err != nil {
+		t.Fatalf("RunMigration() error = %v", err)
+	}
+
+	var timeoutsSet, locksAcquired []string
+	for _, entry := range logger.entries {
+		switch entry.message {
+		case "lock timeout set":
+			timeoutsSet = append(timeoutsSet, entry.String())
+		case "migration acquired its locks":
+			locksAcquired = append(locksAcquired, entry.String())
+		}
+	}
+	if len(timeoutsSet) != 2 {
+		t.Errorf("lock timeout logged %v time(s), want once per attempt", len(timeoutsSet))
+	}
+	if len(locksAcquired) != 1 || !strings.Contains(locksAcquired[0], "attempts2") {
+		t.Errorf("locks acquired = %q, want one entry after 2 attempts", locksAcquired)
+	}
+}
+
+func TestRunnerLogs(t *testing.T) {
+	connector := &fakeConnector{fail: func(query string) bool {
+		return strings.Contains(query, `"posts"`) && strings.HasPrefix(query, "CREATE TABLE")
+	}}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	logger := &recordingLogger{}
+	runner := NewRunner(&memoryIndicator{}, NewBuilderPostgreSQL(), WithLogger(logger))
+
+	if err := runMigrations(runner, db, createTableMigration("001", "users"), createTableMigration("002", "posts")); err == nil {
+		t.Fatal("RunMigration() error = nil, want the failure of 002")
+	}
+
+	messages := make([]string, len(logger.entries))
+	for index, entry := range logger.entries {
+		messages[index] = entry.level + " " + entry.message
+	}
+	want := []string{
+		"INFO migration run started",
+		"INFO migration started",
+		"DEBUG statement executed",
+		"INFO migration acquired its locks",
+		"INFO migration finished",
+		"INFO migration started",
+		"ERROR statement failed",
+		"WARN transaction rolled back",
+		"ERROR migration failed",
+		"WARN compensating migration started",
+		"DEBUG statement executed",
+		"INFO migration acquired its locks",
+		"INFO migration finished",
+		"ERROR migration run failed",
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("messages = %q, want %q", messages, want)
+	}
+}
+
+func TestIndicatorLogs(t *testing.T) {
+	connector := &fakeConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	logger := &recordingLogger{}
+	indicator := NewIndicatorPostgreSQL(db, WithIndicatorLogger(logger))
+
+	indicator.MarkInProgress("001")
+	indicator.MarkUnapplied("001")
+
+	want := []logEntry{
+		{"DEBUG", "migration marked in progress", []any{"migration", "001"}},
+		{"DEBUG", "migration marked unapplied", []any{"migration", "001"}},
+	}
+	if !reflect.DeepEqual(logger.entries, want) {
+		t.Errorf("entries = %v, want %v", logger.entries, want)
+	}
+}
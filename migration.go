@@ -1,7 +1,99 @@
 package gomimi
 
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var ErrPartialMigration = errors.New("migration partially applied")
+
+type Direction uint8
+
+const (
+	DirectionUp Direction = iota
+	DirectionDown
+)
+
+func (direction Direction) String() string {
+	if direction == DirectionDown {
+		return "down"
+	}
+	return "up"
+}
+
 type Migration interface {
 	Up(builder Builder) error
 	Down(builder Builder) error
 	Name() string
 }
+
+// migrationValue returns the migration as it was written by the user,
+// so optional interfaces are also found behind the Func and Reversible adapters
+func migrationValue(migration Migration) any {
+	switch migration := migration.(type) {
+	case funcMigration:
+		return migration.migration
+	case reversibleMigration:
+		return migration.migration
+	default:
+		return migration
+	}
+}
+
+// NoTransactionMigration can be implemented by a migration
+// whose statements cannot run inside a transaction block,
+// they are then executed and committed one by one
+type NoTransactionMigration interface {
+	NoTransaction() bool
+}
+
+// InOrderBuilder can be implemented by a builder that defers the statements
+// which cannot run inside a transaction block,
+// without a transaction there is nothing to defer them past so they are written in order
+type InOrderBuilder interface {
+	WriteInOrder(enable bool)
+}
+
+func isNonTransactional(migration Migration) bool {
+	noTransaction, ok := migrationValue(migration).(NoTransactionMigration)
+	return ok && noTransaction.NoTransaction()
+}
+
+// Executor is what a FuncMigration runs its queries on instead of *sql.Tx,
+// it is the transaction of the migration or a single *sql.Conn when the migration has none,
+// and it writes the statements built so far before every query so they run in order
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// FuncMigration is a migration that can run queries and Go code between the statements it builds,
+// committing or rolling back the executor is left to the Runner
+type FuncMigration interface {
+	Up(ctx context.Context, executor Executor, builder Builder) error
+	Down(ctx context.Context, executor Executor, builder Builder) error
+	Name() string
+}
+
+type funcMigration struct {
+	migration FuncMigration
+}
+
+func Func(migration FuncMigration) Migration {
+	return funcMigration{migration}
+}
+
+func (migration funcMigration) Up(builder Builder) error {
+	return fmt.Errorf(`migration "%v" needs a database connection and can only be run by a Runner`, migration.Name())
+}
+
+func (migration funcMigration) Down(builder Builder) error {
+	return fmt.Errorf(`migration "%v" needs a database connection and can only be run by a Runner`, migration.Name())
+}
+
+func (migration funcMigration) Name() string {
+	return migration.migration.Name()
+}
@@ -0,0 +1,28 @@
+package gomimi
+
+// testMigration is a reversible migration built from a function,
+// it can also suppress lint rules and replace other migrations
+type testMigration struct {
+	name              string
+	change            func(builder Builder)
+	suppressedRuleIDs []string
+}
+
+func (migration testMigration) Change(builder Builder) error {
+	if migration.change != nil {
+		migration.change(builder)
+	}
+	return nil
+}
+
+func (migration testMigration) Name() string {
+	return migration.name
+}
+
+func (migration testMigration) SuppressLint() []string {
+	return migration.suppressedRuleIDs
+}
+
+func newTestMigration(name string, change func(builder Builder)) Migration {
+	return Reversible(testMigration{name: name, change: change})
+}
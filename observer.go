@@ -0,0 +1,133 @@
+package gomimi
+
+import (
+	"context"
+	"regexp"
+	"time"
+)
+
+// the password literals written by CreateRole and AlterRole
+var passwordPattern = regexp.MustCompile(`(?i)\bPASSWORD\s+'(?:[^']|'')*'`)
+
+// Redacted returns the query with its passwords hidden,
+// observers log and trace it instead of the query itself
+func (statement Statement) Redacted() string {
+	return passwordPattern.ReplaceAllString(statement.Query, `PASSWORD '[REDACTED]'`)
+}
+
+// RunReport describes a run once it is over,
+// Current is the current migration of the database, whether this run applied it or not
+type RunReport struct {
+	Current    string
+	Applied    []string
+	RolledBack []string
+	Failed     string
+	Duration   time.Duration
+	Err        error
+}
+
+// RunnerObserver is notified by the runner about everything it does,
+// the context returned by a Before callback is passed to everything that happens until the matching After callback
+type RunnerObserver interface {
+	BeforeRun(ctx context.Context, migrations []Migration) context.Context
+	BeforeMigration(ctx context.Context, name string, direction Direction) context.Context
+	BeforeStatement(ctx context.Context, name string, direction Direction, statement Statement) context.Context
+	AfterStatement(ctx context.Context, name string, direction Direction, statement Statement, duration time.Duration, err error)
+	AfterMigration(ctx context.Context, name string, direction Direction, duration time.Duration, err error)
+	AfterRun(ctx context.Context, report RunReport)
+	OnRollback(ctx context.Context, name string, direction Direction, err error)
+}
+
+// NopObserver can be embedded by observers that only need some of the callbacks
+type NopObserver struct{}
+
+func (NopObserver) BeforeRun(ctx context.Context, migrations []Migration) context.Context {
+	return ctx
+}
+
+func (NopObserver) BeforeMigration(ctx context.Context, name string, direction Direction) context.Context {
+	return ctx
+}
+
+func (NopObserver) BeforeStatement(ctx context.Context, name string, direction Direction, statement Statement) context.Context {
+	return ctx
+}
+
+func (NopObserver) AfterStatement(ctx context.Context, name string, direction Direction, statement Statement, duration time.Duration, err error) {
+}
+
+func (NopObserver) AfterMigration(ctx context.Context, name string, direction Direction, duration time.Duration, err error) {
+}
+
+func (NopObserver) AfterRun(ctx context.Context, report RunReport) {}
+
+func (NopObserver) OnRollback(ctx context.Context, name string, direction Direction, err error) {}
+
+type observers []RunnerObserver
+
+func (observers observers) BeforeRun(ctx context.Context, migrations []Migration) context.Context {
+	for _, observer := range observers {
+		ctx = observer.BeforeRun(ctx, migrations)
+	}
+	return ctx
+}
+
+func (observers observers) BeforeMigration(ctx context.Context, name string, direction Direction) context.Context {
+	for _, observer := range observers {
+		ctx = observer.BeforeMigration(ctx, name, direction)
+	}
+	return ctx
+}
+
+func (observers observers) BeforeStatement(ctx context.Context, name string, direction Direction, statement Statement) context.Context {
+	for _, observer := range observers {
+		ctx = observer.BeforeStatement(ctx, name, direction, statement)
+	}
+	return ctx
+}
+
+func (observers observers) AfterStatement(ctx context.Context, name string, direction Direction, statement Statement, duration time.Duration, err error) {
+	for _, observer := range observers {
+		observer.AfterStatement(ctx, name, direction, statement, duration, err)
+	}
+}
+
+func (observers observers) AfterMigration(ctx context.Context, name string, direction Direction, duration time.Duration, err error) {
+	for _, observer := range observers {
+		observer.AfterMigration(ctx, name, direction, duration, err)
+	}
+}
+
+func (observers observers) AfterRun(ctx context.Context, report RunReport) {
+	for _, observer := range observers {
+		observer.AfterRun(ctx, report)
+	}
+}
+
+func (observers observers) OnRollback(ctx context.Context, name string, direction Direction, err error) {
+	for _, observer := range observers {
+		observer.OnRollback(ctx, name, direction, err)
+	}
+}
+
+// migrationRun executes the statements of a single migration
+// and reports each of them to the observers
+type migrationRun struct {
+	observer  RunnerObserver
+	name      string
+	direction Direction
+}
+
+func (run migrationRun) observe(ctx context.Context, statement Statement, execute func(ctx context.Context) error) error {
+	ctx = run.observer.BeforeStatement(ctx, run.name, run.direction, statement)
+	startedAt := time.Now()
+	err := execute(ctx)
+	run.observer.AfterStatement(ctx, run.name, run.direction, statement, time.Since(startedAt), err)
+	return err
+}
+
+func (run migrationRun) execute(ctx context.Context, executor Executor, statement Statement) error {
+	return run.observe(ctx, statement, func(ctx context.Context) error {
+		return executeStatement(ctx, executor, statement)
+	})
+}
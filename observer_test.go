@@ -0,0 +1,94 @@
+package gomimi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type observerContextKey struct{}
+
+// eventObserver records every callback it gets,
+// along with the run it was given by BeforeRun through the context
+type eventObserver struct {
+	events []string
+}
+
+func (observer *eventObserver) record(ctx context.Context, event string) {
+	if ctx.Value(observerContextKey{}) == nil {
+		event += " without the run context"
+	}
+	observer.events = append(observer.events, event)
+}
+
+func (observer *eventObserver) BeforeRun(ctx context.Context, migrations []Migration) context.Context {
+	ctx = context.WithValue(ctx, observerContextKey{}, true)
+	observer.record(ctx, fmt.Sprintf("BeforeRun %v", len(migrations)))
+	return ctx
+}
+
+func (observer *eventObserver) BeforeMigration(ctx context.Context, name string, direction Direction) context.Context {
+	observer.record(ctx, fmt.Sprintf("BeforeMigration %v %v", name, direction))
+	return ctx
+}
+
+func (observer *eventObserver) BeforeStatement(ctx context.Context, name string, direction Direction, statement Statement) context.Context {
+	observer.record(ctx, fmt.Sprintf("BeforeStatement %v %v", name, direction))
+	return ctx
+}
+
+func (observer *eventObserver) AfterStatement(ctx context.Context, name string, direction Direction, statement Statement, duration time.Duration, err error) {
+	observer.record(ctx, fmt.Sprintf("AfterStatement %v %v %v", name, direction, err))
+}
+
+func (observer *eventObserver) AfterMigration(ctx context.Context, name string, direction Direction, duration time.Duration, err error) {
+	observer.record(ctx, fmt.Sprintf("AfterMigration %v %v %v", name, direction, err))
+}
+
+func (observer *eventObserver) AfterRun(ctx context.Context, report RunReport) {
+	observer.record(ctx, fmt.Sprintf("AfterRun %v %v %v", report.Applied, report.RolledBack, report.Failed))
+}
+
+func (observer *eventObserver) OnRollback(ctx context.Context, name string, direction Direction, err error) {
+	observer.record(ctx, fmt.Sprintf("OnRollback %v %v %v", name, direction, err))
+}
+
+func TestRunnerObserver(t *testing.T) {
+	connector := &fakeConnector{fail: func(query string) bool {
+		return strings.Contains(query, `"posts"`) && strings.HasPrefix(query, "CREATE TABLE")
+	}}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	observer := &eventObserver{}
+	runner := NewRunner(&memoryIndicator{}, NewBuilderPostgreSQL(), WithObserver(observer))
+
+	if err := runMigrations(runner, db, createTableMigration("001", "users"), createTableMigration("002", "posts")); err == nil {
+		t.Fatal("RunMigration() error = nil, want the failure of 002")
+	}
+
+	// the failed migration is rolled back by its down migration
+	want := []string{
+		"BeforeRun 2",
+		"BeforeMigration 001 up",
+		"BeforeStatement 001 up",
+		"AfterStatement 001 up <nil>",
+		"AfterMigration 001 up <nil>",
+		"BeforeMigration 002 up",
+		"BeforeStatement 002 up",
+		"AfterStatement 002 up statement failed",
+		"OnRollback 002 up statement failed",
+		"AfterMigration 002 up statement failed",
+		"BeforeMigration 002 down",
+		"BeforeStatement 002 down",
+		"AfterStatement 002 down <nil>",
+		"AfterMigration 002 down <nil>",
+		"AfterRun [001] [002] ",
+	}
+	if !reflect.DeepEqual(observer.events, want) {
+		t.Errorf("events = %q, want %q", observer.events, want)
+	}
+}
@@ -0,0 +1,86 @@
+package gomimi
+
+import (
+	"fmt"
+	"time"
+)
+
+type PartitionInterval uint8
+
+const (
+	PartitionDaily PartitionInterval = iota
+	PartitionWeekly
+	PartitionMonthly
+	PartitionYearly
+)
+
+type TimePartition struct {
+	Name   string
+	Bounds PartitionBounds
+}
+
+func truncatePartitionTime(start time.Time, interval PartitionInterval) time.Time {
+	year, month, day := start.Date()
+	switch interval {
+	case PartitionWeekly:
+		// weeks start on monday
+		offset := (int(start.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, start.Location())
+	case PartitionMonthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, start.Location())
+	case PartitionYearly:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, start.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, start.Location())
+	}
+}
+
+func nextPartitionTime(start time.Time, interval PartitionInterval) time.Time {
+	switch interval {
+	case PartitionWeekly:
+		return start.AddDate(0, 0, 7)
+	case PartitionMonthly:
+		return start.AddDate(0, 1, 0)
+	case PartitionYearly:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+func partitionNameSuffix(start time.Time, interval PartitionInterval) string {
+	switch interval {
+	case PartitionMonthly:
+		return start.Format("2006_01")
+	case PartitionYearly:
+		return start.Format("2006")
+	default:
+		return start.Format("2006_01_02")
+	}
+}
+
+func TimePartitions(parentTableName string, start time.Time, interval PartitionInterval, count int) []TimePartition {
+	partitions := make([]TimePartition, 0, count)
+
+	from := truncatePartitionTime(start, interval)
+	for i := 0; i < count; i++ {
+		to := nextPartitionTime(from, interval)
+		partitions = append(partitions, TimePartition{
+			Name: fmt.Sprintf(`%v_%v`, parentTableName, partitionNameSuffix(from, interval)),
+			Bounds: PartitionBounds{
+				From: []string{fmt.Sprintf(`'%v'`, from.Format("2006-01-02 15:04:05-07:00"))},
+				To:   []string{fmt.Sprintf(`'%v'`, to.Format("2006-01-02 15:04:05-07:00"))},
+			},
+		})
+		from = to
+	}
+
+	return partitions
+}
+
+func CreateTimePartitions(builder Builder, parentTableName string, start time.Time, interval PartitionInterval, count int) Builder {
+	for _, partition := range TimePartitions(parentTableName, start, interval, count) {
+		builder.CreatePartitionOf(partition.Name, parentTableName, partition.Bounds)
+	}
+	return builder
+}
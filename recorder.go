@@ -0,0 +1,703 @@
+package gomimi
+
+import "github.com/ItsMalma/gomimi/types"
+
+type Operation struct {
+	Method     string
+	SchemaName string
+	ObjectName string
+	ColumnName string
+	Arguments  []any
+}
+
+type Recorder struct {
+	schemaName string
+	operations *[]Operation
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{operations: new([]Operation)}
+}
+
+func (recorder *Recorder) Operations() []Operation {
+	return *recorder.operations
+}
+
+func (recorder *Recorder) record(method string, objectName string, arguments ...any) {
+	*recorder.operations = append(*recorder.operations, Operation{
+		Method:     method,
+		SchemaName: recorder.schemaName,
+		ObjectName: objectName,
+		Arguments:  arguments,
+	})
+}
+
+func (recorder *Recorder) Begin() {}
+
+func (recorder *Recorder) Rollback() {
+	*recorder.operations = nil
+}
+
+func (recorder *Recorder) Commit() string {
+	return ""
+}
+
+func (recorder *Recorder) Statements() []Statement {
+	return nil
+}
+
+func (recorder *Recorder) Deferred() []Statement {
+	return nil
+}
+
+func (recorder *Recorder) SetTimeouts(timeouts Timeouts, local bool) Builder {
+	recorder.record("SetTimeouts", "", timeouts, local)
+	return recorder
+}
+
+func (recorder *Recorder) InSchema(name string) Builder {
+	return &Recorder{schemaName: name, operations: recorder.operations}
+}
+
+func (recorder *Recorder) CreateSchema(name string) Builder {
+	recorder.record("CreateSchema", name)
+	return recorder
+}
+
+func (recorder *Recorder) DropSchema(name string, cascade bool) Builder {
+	recorder.record("DropSchema", name, cascade)
+	return recorder
+}
+
+func (recorder *Recorder) CreateExtension(extension ExtensionDefinition) Builder {
+	recorder.record("CreateExtension", extension.Name, extension)
+	return recorder
+}
+
+func (recorder *Recorder) DropExtension(name string, cascade bool) Builder {
+	recorder.record("DropExtension", name, cascade)
+	return recorder
+}
+
+func (recorder *Recorder) CreateTable(name string, columns []ColumnDefinition, constraints []ConstraintDefinition, options ...TableOption) TableBuilder {
+	recorder.record("CreateTable", name, columns, constraints, options)
+	return &recordingTableBuilder{recorder: recorder, tableName: name}
+}
+
+func (recorder *Recorder) CreatePartitionOf(name string, parentTableName string, bounds PartitionBounds) TableBuilder {
+	recorder.record("CreatePartitionOf", name, parentTableName, bounds)
+	return &recordingTableBuilder{recorder: recorder, tableName: name}
+}
+
+func (recorder *Recorder) AlterTable(name string) TableBuilder {
+	return &recordingTableBuilder{recorder: recorder, tableName: name}
+}
+
+func (recorder *Recorder) DropTable(name string) Builder {
+	recorder.record("DropTable", name)
+	return recorder
+}
+
+func (recorder *Recorder) TruncateTable(name string) Builder {
+	recorder.record("TruncateTable", name)
+	return recorder
+}
+
+func (recorder *Recorder) CreateView(view ViewDefinition) Builder {
+	recorder.record("CreateView", view.Name, view)
+	return recorder
+}
+
+func (recorder *Recorder) CreateOrReplaceView(view ViewDefinition) Builder {
+	recorder.record("CreateOrReplaceView", view.Name, view)
+	return recorder
+}
+
+func (recorder *Recorder) DropView(name string) Builder {
+	recorder.record("DropView", name)
+	return recorder
+}
+
+func (recorder *Recorder) CreateMaterializedView(view MaterializedViewDefinition) Builder {
+	recorder.record("CreateMaterializedView", view.Name, view)
+	return recorder
+}
+
+func (recorder *Recorder) RefreshMaterializedView(name string, concurrently bool) Builder {
+	recorder.record("RefreshMaterializedView", name, concurrently)
+	return recorder
+}
+
+func (recorder *Recorder) DropMaterializedView(name string) Builder {
+	recorder.record("DropMaterializedView", name)
+	return recorder
+}
+
+func (recorder *Recorder) CreateEnum(name string, values ...string) Builder {
+	recorder.record("CreateEnum", name, values)
+	return recorder
+}
+
+func (recorder *Recorder) AlterEnum(name string) EnumBuilder {
+	return &recordingEnumBuilder{recorder: recorder, enumName: name}
+}
+
+func (recorder *Recorder) DropEnum(name string) Builder {
+	recorder.record("DropEnum", name)
+	return recorder
+}
+
+func (recorder *Recorder) CreateSequence(sequence SequenceDefinition) Builder {
+	recorder.record("CreateSequence", sequence.Name, sequence)
+	return recorder
+}
+
+func (recorder *Recorder) AlterSequence(name string) SequenceBuilder {
+	return &recordingSequenceBuilder{recorder: recorder, sequenceName: name}
+}
+
+func (recorder *Recorder) RestartSequence(name string, value int64) Builder {
+	recorder.record("RestartSequence", name, value)
+	return recorder
+}
+
+func (recorder *Recorder) DropSequence(name string) Builder {
+	recorder.record("DropSequence", name)
+	return recorder
+}
+
+func (recorder *Recorder) CreateFunction(function FunctionDefinition) Builder {
+	recorder.record("CreateFunction", function.Name, function)
+	return recorder
+}
+
+func (recorder *Recorder) CreateOrReplaceFunction(function FunctionDefinition) Builder {
+	recorder.record("CreateOrReplaceFunction", function.Name, function)
+	return recorder
+}
+
+func (recorder *Recorder) DropFunction(name string, argumentTypes ...types.Type) Builder {
+	recorder.record("DropFunction", name, argumentTypes)
+	return recorder
+}
+
+func (recorder *Recorder) CreateProcedure(procedure FunctionDefinition) Builder {
+	recorder.record("CreateProcedure", procedure.Name, procedure)
+	return recorder
+}
+
+func (recorder *Recorder) CreateOrReplaceProcedure(procedure FunctionDefinition) Builder {
+	recorder.record("CreateOrReplaceProcedure", procedure.Name, procedure)
+	return recorder
+}
+
+func (recorder *Recorder) DropProcedure(name string, argumentTypes ...types.Type) Builder {
+	recorder.record("DropProcedure", name, argumentTypes)
+	return recorder
+}
+
+func (recorder *Recorder) Grant(grant GrantDefinition) Builder {
+	recorder.record("Grant", "", grant)
+	return recorder
+}
+
+func (recorder *Recorder) Revoke(grant GrantDefinition) Builder {
+	recorder.record("Revoke", "", grant)
+	return recorder
+}
+
+func (recorder *Recorder) CreateRole(role RoleDefinition) Builder {
+	recorder.record("CreateRole", role.Name, role)
+	return recorder
+}
+
+func (recorder *Recorder) AlterRole(role RoleAlteration) Builder {
+	recorder.record("AlterRole", role.Name, role)
+	return recorder
+}
+
+func (recorder *Recorder) DropRole(name string) Builder {
+	recorder.record("DropRole", name)
+	return recorder
+}
+
+func (recorder *Recorder) Insert(tableName string, rows []Row) Builder {
+	recorder.record("Insert", tableName, rows)
+	return recorder
+}
+
+func (recorder *Recorder) Update(tableName string, set Row, where string, arguments ...any) Builder {
+	recorder.record("Update", tableName, set, where, arguments)
+	return recorder
+}
+
+func (recorder *Recorder) Delete(tableName string, where string, arguments ...any) Builder {
+	recorder.record("Delete", tableName, where, arguments)
+	return recorder
+}
+
+func (recorder *Recorder) BatchBackfill(backfill BackfillDefinition) Builder {
+	recorder.record("BatchBackfill", backfill.TableName, backfill)
+	return recorder
+}
+
+type recordingTableBuilder struct {
+	recorder  *Recorder
+	tableName string
+}
+
+func (builder *recordingTableBuilder) record(method string, arguments ...any) TableBuilder {
+	builder.recorder.record("AlterTable."+method, builder.tableName, arguments...)
+	return builder
+}
+
+func (builder *recordingTableBuilder) Rename(newTableName string) TableBuilder {
+	return builder.record("Rename", newTableName)
+}
+
+func (builder *recordingTableBuilder) AddColumn(column ColumnDefinition) TableBuilder {
+	return builder.record("AddColumn", column)
+}
+
+func (builder *recordingTableBuilder) AddConstraint(constraint ConstraintDefinition) TableBuilder {
+	return builder.record("AddConstraint", constraint)
+}
+
+func (builder *recordingTableBuilder) AddIndex(index IndexDefinition) TableBuilder {
+	return builder.record("AddIndex", index)
+}
+
+func (builder *recordingTableBuilder) AlterColumn(columnName string, callback func(alterColumnBuilder AlterColumnBuilder)) TableBuilder {
+	callback(&recordingAlterColumnBuilder{recorder: builder.recorder, tableName: builder.tableName, columnName: columnName})
+	return builder
+}
+
+func (builder *recordingTableBuilder) DropColumn(columnName string) TableBuilder {
+	return builder.record("DropColumn", columnName)
+}
+
+func (builder *recordingTableBuilder) DropConstraint(constraintName string) TableBuilder {
+	return builder.record("DropConstraint", constraintName)
+}
+
+func (builder *recordingTableBuilder) DropIndex(indexName string) TableBuilder {
+	return builder.record("DropIndex", indexName)
+}
+
+func (builder *recordingTableBuilder) RenameColumn(oldColumnName string, newColumnName string) TableBuilder {
+	return builder.record("RenameColumn", oldColumnName, newColumnName)
+}
+
+func (builder *recordingTableBuilder) RenameConstraint(oldConstraintName string, newConstraintName string) TableBuilder {
+	return builder.record("RenameConstraint", oldConstraintName, newConstraintName)
+}
+
+func (builder *recordingTableBuilder) RenameIndex(oldIndexName string, newIndexName string) TableBuilder {
+	return builder.record("RenameIndex", oldIndexName, newIndexName)
+}
+
+func (builder *recordingTableBuilder) ValidateConstraint(constraintName string) TableBuilder {
+	return builder.record("ValidateConstraint", constraintName)
+}
+
+func (builder *recordingTableBuilder) AttachPartition(partitionName string, bounds PartitionBounds) TableBuilder {
+	return builder.record("AttachPartition", partitionName, bounds)
+}
+
+func (builder *recordingTableBuilder) DetachPartition(partitionName string, concurrently bool) TableBuilder {
+	return builder.record("DetachPartition", partitionName, concurrently)
+}
+
+func (builder *recordingTableBuilder) SetComment(comment string) TableBuilder {
+	return builder.record("SetComment", comment)
+}
+
+func (builder *recordingTableBuilder) SetColumnComment(columnName string, comment string) TableBuilder {
+	return builder.record("SetColumnComment", columnName, comment)
+}
+
+func (builder *recordingTableBuilder) CreateTrigger(trigger TriggerDefinition) TableBuilder {
+	return builder.record("CreateTrigger", trigger)
+}
+
+func (builder *recordingTableBuilder) DropTrigger(triggerName string) TableBuilder {
+	return builder.record("DropTrigger", triggerName)
+}
+
+func (builder *recordingTableBuilder) EnableRowLevelSecurity() TableBuilder {
+	return builder.record("EnableRowLevelSecurity")
+}
+
+func (builder *recordingTableBuilder) DisableRowLevelSecurity() TableBuilder {
+	return builder.record("DisableRowLevelSecurity")
+}
+
+func (builder *recordingTableBuilder) ForceRowLevelSecurity() TableBuilder {
+	return builder.record("ForceRowLevelSecurity")
+}
+
+func (builder *recordingTableBuilder) NoForceRowLevelSecurity() TableBuilder {
+	return builder.record("NoForceRowLevelSecurity")
+}
+
+func (builder *recordingTableBuilder) CreatePolicy(policy PolicyDefinition) TableBuilder {
+	return builder.record("CreatePolicy", policy)
+}
+
+func (builder *recordingTableBuilder) DropPolicy(policyName string) TableBuilder {
+	return builder.record("DropPolicy", policyName)
+}
+
+type recordingAlterColumnBuilder struct {
+	recorder   *Recorder
+	tableName  string
+	columnName string
+}
+
+func (builder *recordingAlterColumnBuilder) record(method string, arguments ...any) AlterColumnBuilder {
+	*builder.recorder.operations = append(*builder.recorder.operations, Operation{
+		Method:     "AlterColumn." + method,
+		SchemaName: builder.recorder.schemaName,
+		ObjectName: builder.tableName,
+		ColumnName: builder.columnName,
+		Arguments:  arguments,
+	})
+	return builder
+}
+
+func (builder *recordingAlterColumnBuilder) AlterType(columnType types.Type) AlterColumnBuilder {
+	return builder.record("AlterType", columnType)
+}
+
+func (builder *recordingAlterColumnBuilder) AlterDefault(expression string) AlterColumnBuilder {
+	return builder.record("AlterDefault", expression)
+}
+
+func (builder *recordingAlterColumnBuilder) DropDefault() AlterColumnBuilder {
+	return builder.record("DropDefault")
+}
+
+func (builder *recordingAlterColumnBuilder) SetNullable() AlterColumnBuilder {
+	return builder.record("SetNullable")
+}
+
+func (builder *recordingAlterColumnBuilder) DropNullable() AlterColumnBuilder {
+	return builder.record("DropNullable")
+}
+
+func (builder *recordingAlterColumnBuilder) SetAutoIncrement() AlterColumnBuilder {
+	return builder.record("SetAutoIncrement")
+}
+
+func (builder *recordingAlterColumnBuilder) DropAutoIncrement() AlterColumnBuilder {
+	return builder.record("DropAutoIncrement")
+}
+
+func (builder *recordingAlterColumnBuilder) SetCollation(columnType types.Type, collation string) AlterColumnBuilder {
+	return builder.record("SetCollation", columnType, collation)
+}
+
+func (builder *recordingAlterColumnBuilder) DropExpression() AlterColumnBuilder {
+	return builder.record("DropExpression")
+}
+
+func (builder *recordingAlterColumnBuilder) SetStorage(storage ColumnStorage) AlterColumnBuilder {
+	return builder.record("SetStorage", storage)
+}
+
+func (builder *recordingAlterColumnBuilder) SetCompression(method string) AlterColumnBuilder {
+	return builder.record("SetCompression", method)
+}
+
+func (builder *recordingAlterColumnBuilder) SetStatistics(target int) AlterColumnBuilder {
+	return builder.record("SetStatistics", target)
+}
+
+type recordingEnumBuilder struct {
+	recorder *Recorder
+	enumName string
+}
+
+func (builder *recordingEnumBuilder) record(method string, arguments ...any) EnumBuilder {
+	builder.recorder.record("AlterEnum."+method, builder.enumName, arguments...)
+	return builder
+}
+
+func (builder *recordingEnumBuilder) AddValue(value string) EnumBuilder {
+	return builder.record("AddValue", value)
+}
+
+func (builder *recordingEnumBuilder) AddValueBefore(value string, beforeValue string) EnumBuilder {
+	return builder.record("AddValueBefore", value, beforeValue)
+}
+
+func (builder *recordingEnumBuilder) AddValueAfter(value string, afterValue string) EnumBuilder {
+	return builder.record("AddValueAfter", value, afterValue)
+}
+
+func (builder *recordingEnumBuilder) RenameValue(oldValue string, newValue string) EnumBuilder {
+	return builder.record("RenameValue", oldValue, newValue)
+}
+
+type recordingSequenceBuilder struct {
+	recorder     *Recorder
+	sequenceName string
+}
+
+func (builder *recordingSequenceBuilder) record(method string, arguments ...any) SequenceBuilder {
+	builder.recorder.record("AlterSequence."+method, builder.sequenceName, arguments...)
+	return builder
+}
+
+func (builder *recordingSequenceBuilder) SetType(sequenceType types.Type) SequenceBuilder {
+	return builder.record("SetType", sequenceType)
+}
+
+func (builder *recordingSequenceBuilder) SetIncrement(increment int64) SequenceBuilder {
+	return builder.record("SetIncrement", increment)
+}
+
+func (builder *recordingSequenceBuilder) SetMinValue(minValue int64) SequenceBuilder {
+	return builder.record("SetMinValue", minValue)
+}
+
+func (builder *recordingSequenceBuilder) SetMaxValue(maxValue int64) SequenceBuilder {
+	return builder.record("SetMaxValue", maxValue)
+}
+
+func (builder *recordingSequenceBuilder) SetStart(start int64) SequenceBuilder {
+	return builder.record("SetStart", start)
+}
+
+func (builder *recordingSequenceBuilder) SetCache(cache int64) SequenceBuilder {
+	return builder.record("SetCache", cache)
+}
+
+func (builder *recordingSequenceBuilder) SetCycle(enableCycle bool) SequenceBuilder {
+	return builder.record("SetCycle", enableCycle)
+}
+
+func (builder *recordingSequenceBuilder) SetOwnedBy(tableName string, columnName string) SequenceBuilder {
+	return builder.record("SetOwnedBy", tableName, columnName)
+}
+
+func (builder *recordingSequenceBuilder) DropOwnedBy() SequenceBuilder {
+	return builder.record("DropOwnedBy")
+}
+
+func (builder *recordingSequenceBuilder) Rename(newSequenceName string) SequenceBuilder {
+	builder.record("Rename", newSequenceName)
+	builder.sequenceName = newSequenceName
+	return builder
+}
+
+func (operation Operation) Apply(builder Builder) {
+	if operation.SchemaName != "" {
+		builder = builder.InSchema(operation.SchemaName)
+	}
+
+	arguments := operation.Arguments
+	switch operation.Method {
+	case "SetTimeouts":
+		builder.SetTimeouts(arguments[0].(Timeouts), arguments[1].(bool))
+	case "CreateSchema":
+		builder.CreateSchema(operation.ObjectName)
+	case "DropSchema":
+		builder.DropSchema(operation.ObjectName, arguments[0].(bool))
+	case "CreateExtension":
+		builder.CreateExtension(arguments[0].(ExtensionDefinition))
+	case "DropExtension":
+		builder.DropExtension(operation.ObjectName, arguments[0].(bool))
+	case "CreateTable":
+		builder.CreateTable(
+			operation.ObjectName,
+			arguments[0].([]ColumnDefinition),
+			arguments[1].([]ConstraintDefinition),
+			arguments[2].([]TableOption)...,
+		)
+	case "CreatePartitionOf":
+		builder.CreatePartitionOf(operation.ObjectName, arguments[0].(string), arguments[1].(PartitionBounds))
+	case "DropTable":
+		builder.DropTable(operation.ObjectName)
+	case "TruncateTable":
+		builder.TruncateTable(operation.ObjectName)
+	case "CreateView":
+		builder.CreateView(arguments[0].(ViewDefinition))
+	case "CreateOrReplaceView":
+		builder.CreateOrReplaceView(arguments[0].(ViewDefinition))
+	case "DropView":
+		builder.DropView(operation.ObjectName)
+	case "CreateMaterializedView":
+		builder.CreateMaterializedView(arguments[0].(MaterializedViewDefinition))
+	case "RefreshMaterializedView":
+		builder.RefreshMaterializedView(operation.ObjectName, arguments[0].(bool))
+	case "DropMaterializedView":
+		builder.DropMaterializedView(operation.ObjectName)
+	case "CreateEnum":
+		builder.CreateEnum(operation.ObjectName, arguments[0].([]string)...)
+	case "DropEnum":
+		builder.DropEnum(operation.ObjectName)
+	case "CreateSequence":
+		builder.CreateSequence(arguments[0].(SequenceDefinition))
+	case "RestartSequence":
+		builder.RestartSequence(operation.ObjectName, arguments[0].(int64))
+	case "DropSequence":
+		builder.DropSequence(operation.ObjectName)
+	case "CreateFunction":
+		builder.CreateFunction(arguments[0].(FunctionDefinition))
+	case "CreateOrReplaceFunction":
+		builder.CreateOrReplaceFunction(arguments[0].(FunctionDefinition))
+	case "DropFunction":
+		builder.DropFunction(operation.ObjectName, arguments[0].([]types.Type)...)
+	case "CreateProcedure":
+		builder.CreateProcedure(arguments[0].(FunctionDefinition))
+	case "CreateOrReplaceProcedure":
+		builder.CreateOrReplaceProcedure(arguments[0].(FunctionDefinition))
+	case "DropProcedure":
+		builder.DropProcedure(operation.ObjectName, arguments[0].([]types.Type)...)
+	case "Grant":
+		builder.Grant(arguments[0].(GrantDefinition))
+	case "Revoke":
+		builder.Revoke(arguments[0].(GrantDefinition))
+	case "CreateRole":
+		builder.CreateRole(arguments[0].(RoleDefinition))
+	case "AlterRole":
+		builder.AlterRole(arguments[0].(RoleAlteration))
+	case "DropRole":
+		builder.DropRole(operation.ObjectName)
+	case "Insert":
+		builder.Insert(operation.ObjectName, arguments[0].([]Row))
+	case "Update":
+		builder.Update(operation.ObjectName, arguments[0].(Row), arguments[1].(string), arguments[2].([]any)...)
+	case "Delete":
+		builder.Delete(operation.ObjectName, arguments[0].(string), arguments[1].([]any)...)
+	case "BatchBackfill":
+		builder.BatchBackfill(arguments[0].(BackfillDefinition))
+	case "AlterTable.Rename":
+		builder.AlterTable(operation.ObjectName).Rename(arguments[0].(string))
+	case "AlterTable.AddColumn":
+		builder.AlterTable(operation.ObjectName).AddColumn(arguments[0].(ColumnDefinition))
+	case "AlterTable.AddConstraint":
+		builder.AlterTable(operation.ObjectName).AddConstraint(arguments[0].(ConstraintDefinition))
+	case "AlterTable.AddIndex":
+		builder.AlterTable(operation.ObjectName).AddIndex(arguments[0].(IndexDefinition))
+	case "AlterTable.DropColumn":
+		builder.AlterTable(operation.ObjectName).DropColumn(arguments[0].(string))
+	case "AlterTable.DropConstraint":
+		builder.AlterTable(operation.ObjectName).DropConstraint(arguments[0].(string))
+	case "AlterTable.DropIndex":
+		builder.AlterTable(operation.ObjectName).DropIndex(arguments[0].(string))
+	case "AlterTable.RenameColumn":
+		builder.AlterTable(operation.ObjectName).RenameColumn(arguments[0].(string), arguments[1].(string))
+	case "AlterTable.RenameConstraint":
+		builder.AlterTable(operation.ObjectName).RenameConstraint(arguments[0].(string), arguments[1].(string))
+	case "AlterTable.RenameIndex":
+		builder.AlterTable(operation.ObjectName).RenameIndex(arguments[0].(string), arguments[1].(string))
+	case "AlterTable.ValidateConstraint":
+		builder.AlterTable(operation.ObjectName).ValidateConstraint(arguments[0].(string))
+	case "AlterTable.AttachPartition":
+		builder.AlterTable(operation.ObjectName).AttachPartition(arguments[0].(string), arguments[1].(PartitionBounds))
+	case "AlterTable.DetachPartition":
+		builder.AlterTable(operation.ObjectName).DetachPartition(arguments[0].(string), arguments[1].(bool))
+	case "AlterTable.SetComment":
+		builder.AlterTable(operation.ObjectName).SetComment(arguments[0].(string))
+	case "AlterTable.SetColumnComment":
+		builder.AlterTable(operation.ObjectName).SetColumnComment(arguments[0].(string), arguments[1].(string))
+	case "AlterTable.CreateTrigger":
+		builder.AlterTable(operation.ObjectName).CreateTrigger(arguments[0].(TriggerDefinition))
+	case "AlterTable.DropTrigger":
+		builder.AlterTable(operation.ObjectName).DropTrigger(arguments[0].(string))
+	case "AlterTable.EnableRowLevelSecurity":
+		builder.AlterTable(operation.ObjectName).EnableRowLevelSecurity()
+	case "AlterTable.DisableRowLevelSecurity":
+		builder.AlterTable(operation.ObjectName).DisableRowLevelSecurity()
+	case "AlterTable.ForceRowLevelSecurity":
+		builder.AlterTable(operation.ObjectName).ForceRowLevelSecurity()
+	case "AlterTable.NoForceRowLevelSecurity":
+		builder.AlterTable(operation.ObjectName).NoForceRowLevelSecurity()
+	case "AlterTable.CreatePolicy":
+		builder.AlterTable(operation.ObjectName).CreatePolicy(arguments[0].(PolicyDefinition))
+	case "AlterTable.DropPolicy":
+		builder.AlterTable(operation.ObjectName).DropPolicy(arguments[0].(string))
+	case "AlterColumn.AlterType":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.AlterType(arguments[0].(types.Type))
+		})
+	case "AlterColumn.AlterDefault":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.AlterDefault(arguments[0].(string))
+		})
+	case "AlterColumn.DropDefault":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.DropDefault()
+		})
+	case "AlterColumn.SetNullable":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.SetNullable()
+		})
+	case "AlterColumn.DropNullable":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.DropNullable()
+		})
+	case "AlterColumn.SetAutoIncrement":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.SetAutoIncrement()
+		})
+	case "AlterColumn.DropAutoIncrement":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.DropAutoIncrement()
+		})
+	case "AlterColumn.SetCollation":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.SetCollation(arguments[0].(types.Type), arguments[1].(string))
+		})
+	case "AlterColumn.DropExpression":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.DropExpression()
+		})
+	case "AlterColumn.SetStorage":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.SetStorage(arguments[0].(ColumnStorage))
+		})
+	case "AlterColumn.SetCompression":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.SetCompression(arguments[0].(string))
+		})
+	case "AlterColumn.SetStatistics":
+		operation.alterColumn(builder, func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.SetStatistics(arguments[0].(int))
+		})
+	case "AlterEnum.AddValue":
+		builder.AlterEnum(operation.ObjectName).AddValue(arguments[0].(string))
+	case "AlterEnum.AddValueBefore":
+		builder.AlterEnum(operation.ObjectName).AddValueBefore(arguments[0].(string), arguments[1].(string))
+	case "AlterEnum.AddValueAfter":
+		builder.AlterEnum(operation.ObjectName).AddValueAfter(arguments[0].(string), arguments[1].(string))
+	case "AlterEnum.RenameValue":
+		builder.AlterEnum(operation.ObjectName).RenameValue(arguments[0].(string), arguments[1].(string))
+	case "AlterSequence.SetType":
+		builder.AlterSequence(operation.ObjectName).SetType(arguments[0].(types.Type))
+	case "AlterSequence.SetIncrement":
+		builder.AlterSequence(operation.ObjectName).SetIncrement(arguments[0].(int64))
+	case "AlterSequence.SetMinValue":
+		builder.AlterSequence(operation.ObjectName).SetMinValue(arguments[0].(int64))
+	case "AlterSequence.SetMaxValue":
+		builder.AlterSequence(operation.ObjectName).SetMaxValue(arguments[0].(int64))
+	case "AlterSequence.SetStart":
+		builder.AlterSequence(operation.ObjectName).SetStart(arguments[0].(int64))
+	case "AlterSequence.SetCache":
+		builder.AlterSequence(operation.ObjectName).SetCache(arguments[0].(int64))
+	case "AlterSequence.SetCycle":
+		builder.AlterSequence(operation.ObjectName).SetCycle(arguments[0].(bool))
+	case "AlterSequence.SetOwnedBy":
+		builder.AlterSequence(operation.ObjectName).SetOwnedBy(arguments[0].(string), arguments[1].(string))
+	case "AlterSequence.DropOwnedBy":
+		builder.AlterSequence(operation.ObjectName).DropOwnedBy()
+	case "AlterSequence.Rename":
+		builder.AlterSequence(operation.ObjectName).Rename(arguments[0].(string))
+	}
+}
+
+func (operation Operation) alterColumn(builder Builder, callback func(alterColumnBuilder AlterColumnBuilder)) {
+	builder.AlterTable(operation.ObjectName).AlterColumn(operation.ColumnName, callback)
+}
@@ -0,0 +1,103 @@
+package gomimi
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrDirty = errors.New("database is dirty")
+
+func (runner Runner) checkDirty() error {
+	history, ok := runner.indicator.(HistoryIndicator)
+	if !ok {
+		return nil
+	}
+	for _, appliedMigration := range history.Applied() {
+		if appliedMigration.Dirty {
+			return fmt.Errorf(`%w: migration "%v" did not finish, repair it and use Force, MarkApplied or MarkUnapplied`, ErrDirty, appliedMigration.Name)
+		}
+	}
+	return nil
+}
+
+func (runner Runner) history() HistoryIndicator {
+	history, ok := runner.indicator.(HistoryIndicator)
+	if !ok {
+		panic(fmt.Errorf("indicator does not record the migration history"))
+	}
+	return history
+}
+
+// markCurrent records the migration as applied and makes it the current one,
+// in a single step when the indicator records the history
+func (runner Runner) markCurrent(name string) {
+	if history, ok := runner.indicator.(HistoryIndicator); ok {
+		history.MarkCurrent(name)
+		return
+	}
+	runner.indicator.Change(name)
+}
+
+func migrationIndex(name string, migrations []Migration) int {
+	for index, migration := range migrations {
+		if migration.Name() == name {
+			return index
+		}
+	}
+	return -1
+}
+
+// Force makes the given migration the current one and clears every dirty migration,
+// an empty name means no migration is applied
+func (runner Runner) Force(name string) {
+	if history, ok := runner.indicator.(HistoryIndicator); ok {
+		for _, appliedMigration := range history.Applied() {
+			if appliedMigration.Dirty && appliedMigration.Name != name {
+				history.MarkUnapplied(appliedMigration.Name)
+			}
+		}
+	}
+	if name != "" {
+		runner.markCurrent(name)
+	} else {
+		runner.indicator.Change(name)
+	}
+	runner.log().Warn("migration state forced", "migration", name)
+}
+
+// MarkApplied records the migration as applied without running it,
+// it becomes the current one unless a later migration is already current
+func (runner Runner) MarkApplied(name string, migrations ...Migration) {
+	index := migrationIndex(name, migrations)
+	if index < 0 {
+		panic(fmt.Errorf(`migration with name "%v" not found`, name))
+	}
+
+	history := runner.history()
+
+	currentMigrationName := runner.indicator.Current()
+	if currentMigrationName == "" || migrationIndex(currentMigrationName, migrations) < index {
+		history.MarkCurrent(name)
+	} else {
+		history.MarkApplied(name)
+	}
+}
+
+// MarkUnapplied removes the migration from the history without running it,
+// when it is the current one the migration before it becomes current
+func (runner Runner) MarkUnapplied(name string, migrations ...Migration) {
+	index := migrationIndex(name, migrations)
+	if index < 0 {
+		panic(fmt.Errorf(`migration with name "%v" not found`, name))
+	}
+
+	runner.history().MarkUnapplied(name)
+
+	if runner.indicator.Current() == name {
+		previousMigrationName := ""
+		if index > 0 {
+			previousMigrationName = migrations[index-1].Name()
+		}
+		runner.indicator.Change(previousMigrationName)
+	}
+}
@@ -0,0 +1,275 @@
+package gomimi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ItsMalma/gomimi/types"
+)
+
+var ErrIrreversibleOperation = errors.New("irreversible operation")
+
+type ReversibleMigration interface {
+	Change(builder Builder) error
+	Name() string
+}
+
+type reversibleMigration struct {
+	migration ReversibleMigration
+}
+
+func Reversible(migration ReversibleMigration) Migration {
+	return reversibleMigration{migration}
+}
+
+func (migration reversibleMigration) Up(builder Builder) error {
+	return migration.migration.Change(builder)
+}
+
+func (migration reversibleMigration) Down(builder Builder) error {
+	recorder := NewRecorder()
+	if err := migration.migration.Change(recorder); err != nil {
+		return err
+	}
+
+	operations, err := ReverseOperations(recorder.Operations())
+	if err != nil {
+		return fmt.Errorf(`migration "%v": %w`, migration.migration.Name(), err)
+	}
+
+	for _, operation := range operations {
+		operation.Apply(builder)
+	}
+
+	return nil
+}
+
+func (migration reversibleMigration) Name() string {
+	return migration.migration.Name()
+}
+
+func ReverseOperations(operations []Operation) ([]Operation, error) {
+	reversedOperations := make([]Operation, 0, len(operations))
+	// timeouts apply to the whole migration in either direction,
+	// so they are kept in front instead of being reversed
+	for _, operation := range operations {
+		if operation.Method == "SetTimeouts" {
+			reversedOperations = append(reversedOperations, operation)
+		}
+	}
+	for index := len(operations) - 1; index >= 0; index-- {
+		if operations[index].Method == "SetTimeouts" {
+			continue
+		}
+		inverseOperations, err := operations[index].inverse(operations[:index])
+		if err != nil {
+			return nil, err
+		}
+		reversedOperations = append(reversedOperations, inverseOperations...)
+	}
+	return reversedOperations, nil
+}
+
+func (operation Operation) with(method string, objectName string, arguments ...any) Operation {
+	return Operation{
+		Method:     method,
+		SchemaName: operation.SchemaName,
+		ObjectName: objectName,
+		ColumnName: operation.ColumnName,
+		Arguments:  arguments,
+	}
+}
+
+func (operation Operation) irreversible() error {
+	if operation.ColumnName != "" {
+		return fmt.Errorf(`%w: %v of column "%v" on "%v"`, ErrIrreversibleOperation, operation.Method, operation.ColumnName, operation.ObjectName)
+	}
+	if operation.ObjectName != "" {
+		return fmt.Errorf(`%w: %v on "%v"`, ErrIrreversibleOperation, operation.Method, operation.ObjectName)
+	}
+	return fmt.Errorf(`%w: %v`, ErrIrreversibleOperation, operation.Method)
+}
+
+func (operation Operation) inverse(previousOperations []Operation) ([]Operation, error) {
+	arguments := operation.Arguments
+	switch operation.Method {
+	case "CreateSchema":
+		return []Operation{operation.with("DropSchema", operation.ObjectName, false)}, nil
+	case "CreateExtension":
+		return []Operation{operation.with("DropExtension", operation.ObjectName, false)}, nil
+	case "CreateTable", "CreatePartitionOf":
+		return []Operation{operation.with("DropTable", operation.ObjectName)}, nil
+	case "DropTable":
+		for index := len(previousOperations) - 1; index >= 0; index-- {
+			previousOperation := previousOperations[index]
+			if previousOperation.Method == "CreateTable" && previousOperation.SchemaName == operation.SchemaName && previousOperation.ObjectName == operation.ObjectName {
+				return []Operation{previousOperation}, nil
+			}
+		}
+		return nil, fmt.Errorf(`%w: DropTable "%v" has no recorded table definition`, ErrIrreversibleOperation, operation.ObjectName)
+	case "CreateView":
+		return []Operation{operation.with("DropView", operation.ObjectName)}, nil
+	case "CreateMaterializedView":
+		return []Operation{operation.with("DropMaterializedView", operation.ObjectName)}, nil
+	case "RefreshMaterializedView":
+		return nil, nil
+	case "CreateEnum":
+		return []Operation{operation.with("DropEnum", operation.ObjectName)}, nil
+	case "CreateSequence":
+		return []Operation{operation.with("DropSequence", operation.ObjectName)}, nil
+	case "CreateFunction", "CreateProcedure":
+		function := arguments[0].(FunctionDefinition)
+		argumentTypes := make([]types.Type, 0, len(function.Arguments))
+		for _, argument := range function.Arguments {
+			argumentTypes = append(argumentTypes, argument.Type)
+		}
+		if operation.Method == "CreateFunction" {
+			return []Operation{operation.with("DropFunction", operation.ObjectName, argumentTypes)}, nil
+		}
+		return []Operation{operation.with("DropProcedure", operation.ObjectName, argumentTypes)}, nil
+	case "Grant":
+		// revoking with the grant option would only take the option back and leave the privilege
+		grant := arguments[0].(GrantDefinition)
+		grant.WithGrantOption = false
+		return []Operation{operation.with("Revoke", operation.ObjectName, grant)}, nil
+	case "Revoke":
+		return []Operation{operation.with("Grant", operation.ObjectName, arguments...)}, nil
+	case "CreateRole":
+		return []Operation{operation.with("DropRole", operation.ObjectName)}, nil
+	case "AlterTable.Rename":
+		return []Operation{operation.with("AlterTable.Rename", arguments[0].(string), operation.ObjectName)}, nil
+	case "AlterTable.AddColumn":
+		return []Operation{operation.with("AlterTable.DropColumn", operation.ObjectName, arguments[0].(ColumnDefinition).Name)}, nil
+	case "AlterTable.AddConstraint":
+		constraint := arguments[0].(ConstraintDefinition)
+		if constraint.Name == "" {
+			return nil, fmt.Errorf(`%w: AddConstraint on "%v" without a constraint name`, ErrIrreversibleOperation, operation.ObjectName)
+		}
+		return []Operation{operation.with("AlterTable.DropConstraint", operation.ObjectName, constraint.Name)}, nil
+	case "AlterTable.AddIndex":
+		index := arguments[0].(IndexDefinition)
+		if index.Name == "" {
+			return nil, fmt.Errorf(`%w: AddIndex on "%v" without an index name`, ErrIrreversibleOperation, operation.ObjectName)
+		}
+		return []Operation{operation.with("AlterTable.DropIndex", operation.ObjectName, index.Name)}, nil
+	case "AlterTable.DropColumn":
+		if column, found := findRecordedColumn(previousOperations, operation, arguments[0].(string)); found {
+			return []Operation{operation.with("AlterTable.AddColumn", operation.ObjectName, column)}, nil
+		}
+		return nil, fmt.Errorf(
+			`%w: DropColumn "%v" on "%v" has no recorded column definition`,
+			ErrIrreversibleOperation,
+			arguments[0],
+			operation.ObjectName,
+		)
+	case "AlterTable.DropConstraint":
+		if constraint, found := findRecordedConstraint(previousOperations, operation, arguments[0].(string)); found {
+			return []Operation{operation.with("AlterTable.AddConstraint", operation.ObjectName, constraint)}, nil
+		}
+		return nil, fmt.Errorf(
+			`%w: DropConstraint "%v" on "%v" has no recorded constraint definition`,
+			ErrIrreversibleOperation,
+			arguments[0],
+			operation.ObjectName,
+		)
+	case "AlterTable.DropIndex":
+		if index, found := findRecordedIndex(previousOperations, operation, arguments[0].(string)); found {
+			return []Operation{operation.with("AlterTable.AddIndex", operation.ObjectName, index)}, nil
+		}
+		return nil, fmt.Errorf(
+			`%w: DropIndex "%v" on "%v" has no recorded index definition`,
+			ErrIrreversibleOperation,
+			arguments[0],
+			operation.ObjectName,
+		)
+	case "AlterTable.RenameColumn", "AlterTable.RenameConstraint", "AlterTable.RenameIndex":
+		return []Operation{operation.with(operation.Method, operation.ObjectName, arguments[1], arguments[0])}, nil
+	case "AlterTable.ValidateConstraint":
+		return nil, nil
+	case "AlterTable.AttachPartition":
+		return []Operation{operation.with("AlterTable.DetachPartition", operation.ObjectName, arguments[0], false)}, nil
+	case "AlterTable.CreateTrigger":
+		return []Operation{operation.with("AlterTable.DropTrigger", operation.ObjectName, arguments[0].(TriggerDefinition).Name)}, nil
+	case "AlterTable.EnableRowLevelSecurity":
+		return []Operation{operation.with("AlterTable.DisableRowLevelSecurity", operation.ObjectName)}, nil
+	case "AlterTable.DisableRowLevelSecurity":
+		return []Operation{operation.with("AlterTable.EnableRowLevelSecurity", operation.ObjectName)}, nil
+	case "AlterTable.ForceRowLevelSecurity":
+		return []Operation{operation.with("AlterTable.NoForceRowLevelSecurity", operation.ObjectName)}, nil
+	case "AlterTable.NoForceRowLevelSecurity":
+		return []Operation{operation.with("AlterTable.ForceRowLevelSecurity", operation.ObjectName)}, nil
+	case "AlterTable.CreatePolicy":
+		return []Operation{operation.with("AlterTable.DropPolicy", operation.ObjectName, arguments[0].(PolicyDefinition).Name)}, nil
+	case "AlterColumn.SetNullable":
+		return []Operation{operation.with("AlterColumn.DropNullable", operation.ObjectName)}, nil
+	case "AlterColumn.DropNullable":
+		return []Operation{operation.with("AlterColumn.SetNullable", operation.ObjectName)}, nil
+	case "AlterColumn.SetAutoIncrement":
+		return []Operation{operation.with("AlterColumn.DropAutoIncrement", operation.ObjectName)}, nil
+	case "AlterEnum.RenameValue":
+		return []Operation{operation.with(operation.Method, operation.ObjectName, arguments[1], arguments[0])}, nil
+	case "AlterSequence.Rename":
+		return []Operation{operation.with("AlterSequence.Rename", arguments[0].(string), operation.ObjectName)}, nil
+	default:
+		return nil, operation.irreversible()
+	}
+}
+
+func findRecordedColumn(previousOperations []Operation, operation Operation, columnName string) (ColumnDefinition, bool) {
+	for index := len(previousOperations) - 1; index >= 0; index-- {
+		previousOperation := previousOperations[index]
+		if previousOperation.SchemaName != operation.SchemaName || previousOperation.ObjectName != operation.ObjectName {
+			continue
+		}
+		switch previousOperation.Method {
+		case "CreateTable":
+			for _, column := range previousOperation.Arguments[0].([]ColumnDefinition) {
+				if column.Name == columnName {
+					return column, true
+				}
+			}
+		case "AlterTable.AddColumn":
+			if column := previousOperation.Arguments[0].(ColumnDefinition); column.Name == columnName {
+				return column, true
+			}
+		}
+	}
+	return ColumnDefinition{}, false
+}
+
+func findRecordedConstraint(previousOperations []Operation, operation Operation, constraintName string) (ConstraintDefinition, bool) {
+	for index := len(previousOperations) - 1; index >= 0; index-- {
+		previousOperation := previousOperations[index]
+		if previousOperation.SchemaName != operation.SchemaName || previousOperation.ObjectName != operation.ObjectName {
+			continue
+		}
+		switch previousOperation.Method {
+		case "CreateTable":
+			for _, constraint := range previousOperation.Arguments[1].([]ConstraintDefinition) {
+				if constraint.Name == constraintName {
+					return constraint, true
+				}
+			}
+		case "AlterTable.AddConstraint":
+			if constraint := previousOperation.Arguments[0].(ConstraintDefinition); constraint.Name == constraintName {
+				return constraint, true
+			}
+		}
+	}
+	return ConstraintDefinition{}, false
+}
+
+func findRecordedIndex(previousOperations []Operation, operation Operation, indexName string) (IndexDefinition, bool) {
+	for index := len(previousOperations) - 1; index >= 0; index-- {
+		previousOperation := previousOperations[index]
+		if previousOperation.SchemaName != operation.SchemaName || previousOperation.ObjectName != operation.ObjectName {
+			continue
+		}
+		if previousOperation.Method == "AlterTable.AddIndex" {
+			if recordedIndex := previousOperation.Arguments[0].(IndexDefinition); recordedIndex.Name == indexName {
+				return recordedIndex, true
+			}
+		}
+	}
+	return IndexDefinition{}, false
+}
@@ -0,0 +1,306 @@
+package gomimi
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ItsMalma/gomimi/types"
+)
+
+func TestReverseOperations(t *testing.T) {
+	usersTable := Operation{
+		Method:     "CreateTable",
+		ObjectName: "users",
+		Arguments: []any{
+			[]ColumnDefinition{{Name: "id", Type: types.BigInt()}, {Name: "email", Type: types.Text()}},
+			[]ConstraintDefinition{{Name: "users_email_key", Type: ConstraintUnique, ColumnNames: []string{"email"}}},
+			[]TableOption(nil),
+		},
+	}
+	emailIndex := IndexDefinition{Name: "users_email_idx", ColumnNames: []string{"email"}}
+	function := FunctionDefinition{Name: "touch", Arguments: []FunctionArgumentDefinition{{Name: "at", Type: types.Timestamp(true)}}}
+	grant := GrantDefinition{Privileges: []string{"SELECT"}, ObjectType: GrantTable, ObjectNames: []string{"users"}, RoleNames: []string{"reader"}}
+	grantWithGrantOption := grant
+	grantWithGrantOption.WithGrantOption = true
+
+	tests := []struct {
+		name       string
+		operations []Operation
+		want       []Operation
+	}{
+		{
+			name:       "CreateSchema",
+			operations: []Operation{{Method: "CreateSchema", ObjectName: "app"}},
+			want:       []Operation{{Method: "DropSchema", ObjectName: "app", Arguments: []any{false}}},
+		},
+		{
+			name:       "CreateExtension",
+			operations: []Operation{{Method: "CreateExtension", ObjectName: "citext", Arguments: []any{ExtensionDefinition{Name: "citext"}}}},
+			want:       []Operation{{Method: "DropExtension", ObjectName: "citext", Arguments: []any{false}}},
+		},
+		{
+			name:       "CreateTable",
+			operations: []Operation{usersTable},
+			want:       []Operation{{Method: "DropTable", ObjectName: "users"}},
+		},
+		{
+			name:       "CreatePartitionOf",
+			operations: []Operation{{Method: "CreatePartitionOf", ObjectName: "events_2024", Arguments: []any{"events", PartitionBounds{}}}},
+			want:       []Operation{{Method: "DropTable", ObjectName: "events_2024"}},
+		},
+		{
+			name:       "DropTable restores the recorded table",
+			operations: []Operation{usersTable, {Method: "DropTable", ObjectName: "users"}},
+			want:       []Operation{usersTable, {Method: "DropTable", ObjectName: "users"}},
+		},
+		{
+			name:       "CreateView",
+			operations: []Operation{{Method: "CreateView", ObjectName: "active_users", Arguments: []any{ViewDefinition{Name: "active_users"}}}},
+			want:       []Operation{{Method: "DropView", ObjectName: "active_users"}},
+		},
+		{
+			name:       "CreateMaterializedView",
+			operations: []Operation{{Method: "CreateMaterializedView", ObjectName: "totals", Arguments: []any{MaterializedViewDefinition{Name: "totals"}}}},
+			want:       []Operation{{Method: "DropMaterializedView", ObjectName: "totals"}},
+		},
+		{
+			name:       "RefreshMaterializedView has nothing to undo",
+			operations: []Operation{{Method: "RefreshMaterializedView", ObjectName: "totals", Arguments: []any{false}}},
+			want:       []Operation{},
+		},
+		{
+			name:       "CreateEnum",
+			operations: []Operation{{Method: "CreateEnum", ObjectName: "mood", Arguments: []any{[]string{"happy"}}}},
+			want:       []Operation{{Method: "DropEnum", ObjectName: "mood"}},
+		},
+		{
+			name:       "CreateSequence",
+			operations: []Operation{{Method: "CreateSequence", ObjectName: "ids", Arguments: []any{SequenceDefinition{Name: "ids"}}}},
+			want:       []Operation{{Method: "DropSequence", ObjectName: "ids"}},
+		},
+		{
+			name:       "CreateFunction",
+			operations: []Operation{{Method: "CreateFunction", ObjectName: "touch", Arguments: []any{function}}},
+			want:       []Operation{{Method: "DropFunction", ObjectName: "touch", Arguments: []any{[]types.Type{types.Timestamp(true)}}}},
+		},
+		{
+			name:       "CreateProcedure",
+			operations: []Operation{{Method: "CreateProcedure", ObjectName: "touch", Arguments: []any{function}}},
+			want:       []Operation{{Method: "DropProcedure", ObjectName: "touch", Arguments: []any{[]types.Type{types.Timestamp(true)}}}},
+		},
+		{
+			name:       "Grant",
+			operations: []Operation{{Method: "Grant", Arguments: []any{grant}}},
+			want:       []Operation{{Method: "Revoke", Arguments: []any{grant}}},
+		},
+		{
+			name:       "Grant with grant option",
+			operations: []Operation{{Method: "Grant", Arguments: []any{grantWithGrantOption}}},
+			want:       []Operation{{Method: "Revoke", Arguments: []any{grant}}},
+		},
+		{
+			name:       "Revoke",
+			operations: []Operation{{Method: "Revoke", Arguments: []any{grant}}},
+			want:       []Operation{{Method: "Grant", Arguments: []any{grant}}},
+		},
+		{
+			name:       "CreateRole",
+			operations: []Operation{{Method: "CreateRole", ObjectName: "reader", Arguments: []any{RoleDefinition{Name: "reader"}}}},
+			want:       []Operation{{Method: "DropRole", ObjectName: "reader"}},
+		},
+		{
+			name:       "AlterTable.Rename",
+			operations: []Operation{{Method: "AlterTable.Rename", ObjectName: "users", Arguments: []any{"accounts"}}},
+			want:       []Operation{{Method: "AlterTable.Rename", ObjectName: "accounts", Arguments: []any{"users"}}},
+		},
+		{
+			name:       "AlterTable.AddColumn",
+			operations: []Operation{{Method: "AlterTable.AddColumn", ObjectName: "users", Arguments: []any{ColumnDefinition{Name: "age", Type: types.Integer()}}}},
+			want:       []Operation{{Method: "AlterTable.DropColumn", ObjectName: "users", Arguments: []any{"age"}}},
+		},
+		{
+			name:       "AlterTable.AddConstraint",
+			operations: []Operation{{Method: "AlterTable.AddConstraint", ObjectName: "users", Arguments: []any{ConstraintDefinition{Name: "users_age_check", Type: ConstraintCheck}}}},
+			want:       []Operation{{Method: "AlterTable.DropConstraint", ObjectName: "users", Arguments: []any{"users_age_check"}}},
+		},
+		{
+			name:       "AlterTable.AddIndex",
+			operations: []Operation{{Method: "AlterTable.AddIndex", ObjectName: "users", Arguments: []any{emailIndex}}},
+			want:       []Operation{{Method: "AlterTable.DropIndex", ObjectName: "users", Arguments: []any{"users_email_idx"}}},
+		},
+		{
+			name:       "AlterTable.DropColumn restores the recorded column",
+			operations: []Operation{usersTable, {Method: "AlterTable.DropColumn", ObjectName: "users", Arguments: []any{"email"}}},
+			want: []Operation{
+				{Method: "AlterTable.AddColumn", ObjectName: "users", Arguments: []any{ColumnDefinition{Name: "email", Type: types.Text()}}},
+				{Method: "DropTable", ObjectName: "users"},
+			},
+		},
+		{
+			name:       "AlterTable.DropConstraint restores the recorded constraint",
+			operations: []Operation{usersTable, {Method: "AlterTable.DropConstraint", ObjectName: "users", Arguments: []any{"users_email_key"}}},
+			want: []Operation{
+				{Method: "AlterTable.AddConstraint", ObjectName: "users", Arguments: []any{ConstraintDefinition{Name: "users_email_key", Type: ConstraintUnique, ColumnNames: []string{"email"}}}},
+				{Method: "DropTable", ObjectName: "users"},
+			},
+		},
+		{
+			name: "AlterTable.DropIndex restores the recorded index",
+			operations: []Operation{
+				{Method: "AlterTable.AddIndex", ObjectName: "users", Arguments: []any{emailIndex}},
+				{Method: "AlterTable.DropIndex", ObjectName: "users", Arguments: []any{"users_email_idx"}},
+			},
+			want: []Operation{
+				{Method: "AlterTable.AddIndex", ObjectName: "users", Arguments: []any{emailIndex}},
+				{Method: "AlterTable.DropIndex", ObjectName: "users", Arguments: []any{"users_email_idx"}},
+			},
+		},
+		{
+			name:       "AlterTable.RenameColumn",
+			operations: []Operation{{Method: "AlterTable.RenameColumn", ObjectName: "users", Arguments: []any{"email", "mail"}}},
+			want:       []Operation{{Method: "AlterTable.RenameColumn", ObjectName: "users", Arguments: []any{"mail", "email"}}},
+		},
+		{
+			name:       "AlterTable.RenameConstraint",
+			operations: []Operation{{Method: "AlterTable.RenameConstraint", ObjectName: "users", Arguments: []any{"a", "b"}}},
+			want:       []Operation{{Method: "AlterTable.RenameConstraint", ObjectName: "users", Arguments: []any{"b", "a"}}},
+		},
+		{
+			name:       "AlterTable.RenameIndex",
+			operations: []Operation{{Method: "AlterTable.RenameIndex", ObjectName: "users", Arguments: []any{"a", "b"}}},
+			want:       []Operation{{Method: "AlterTable.RenameIndex", ObjectName: "users", Arguments: []any{"b", "a"}}},
+		},
+		{
+			name:       "AlterTable.ValidateConstraint has nothing to undo",
+			operations: []Operation{{Method: "AlterTable.ValidateConstraint", ObjectName: "users", Arguments: []any{"users_age_check"}}},
+			want:       []Operation{},
+		},
+		{
+			name:       "AlterTable.AttachPartition",
+			operations: []Operation{{Method: "AlterTable.AttachPartition", ObjectName: "events", Arguments: []any{"events_2024", PartitionBounds{}}}},
+			want:       []Operation{{Method: "AlterTable.DetachPartition", ObjectName: "events", Arguments: []any{"events_2024", false}}},
+		},
+		{
+			name:       "AlterTable.CreateTrigger",
+			operations: []Operation{{Method: "AlterTable.CreateTrigger", ObjectName: "users", Arguments: []any{TriggerDefinition{Name: "users_touch"}}}},
+			want:       []Operation{{Method: "AlterTable.DropTrigger", ObjectName: "users", Arguments: []any{"users_touch"}}},
+		},
+		{
+			name:       "AlterTable.EnableRowLevelSecurity",
+			operations: []Operation{{Method: "AlterTable.EnableRowLevelSecurity", ObjectName: "users"}},
+			want:       []Operation{{Method: "AlterTable.DisableRowLevelSecurity", ObjectName: "users"}},
+		},
+		{
+			name:       "AlterTable.DisableRowLevelSecurity",
+			operations: []Operation{{Method: "AlterTable.DisableRowLevelSecurity", ObjectName: "users"}},
+			want:       []Operation{{Method: "AlterTable.EnableRowLevelSecurity", ObjectName: "users"}},
+		},
+		{
+			name:       "AlterTable.ForceRowLevelSecurity",
+			operations: []Operation{{Method: "AlterTable.ForceRowLevelSecurity", ObjectName: "users"}},
+			want:       []Operation{{Method: "AlterTable.NoForceRowLevelSecurity", ObjectName: "users"}},
+		},
+		{
+			name:       "AlterTable.NoForceRowLevelSecurity",
+			operations: []Operation{{Method: "AlterTable.NoForceRowLevelSecurity", ObjectName: "users"}},
+			want:       []Operation{{Method: "AlterTable.ForceRowLevelSecurity", ObjectName: "users"}},
+		},
+		{
+			name:       "AlterTable.CreatePolicy",
+			operations: []Operation{{Method: "AlterTable.CreatePolicy", ObjectName: "users", Arguments: []any{PolicyDefinition{Name: "own_rows"}}}},
+			want:       []Operation{{Method: "AlterTable.DropPolicy", ObjectName: "users", Arguments: []any{"own_rows"}}},
+		},
+		{
+			name:       "AlterColumn.SetNullable",
+			operations: []Operation{{Method: "AlterColumn.SetNullable", ObjectName: "users", ColumnName: "email"}},
+			want:       []Operation{{Method: "AlterColumn.DropNullable", ObjectName: "users", ColumnName: "email"}},
+		},
+		{
+			name:       "AlterColumn.DropNullable",
+			operations: []Operation{{Method: "AlterColumn.DropNullable", ObjectName: "users", ColumnName: "email"}},
+			want:       []Operation{{Method: "AlterColumn.SetNullable", ObjectName: "users", ColumnName: "email"}},
+		},
+		{
+			name:       "AlterColumn.SetAutoIncrement",
+			operations: []Operation{{Method: "AlterColumn.SetAutoIncrement", ObjectName: "users", ColumnName: "id"}},
+			want:       []Operation{{Method: "AlterColumn.DropAutoIncrement", ObjectName: "users", ColumnName: "id"}},
+		},
+		{
+			name:       "AlterEnum.RenameValue",
+			operations: []Operation{{Method: "AlterEnum.RenameValue", ObjectName: "mood", Arguments: []any{"sad", "blue"}}},
+			want:       []Operation{{Method: "AlterEnum.RenameValue", ObjectName: "mood", Arguments: []any{"blue", "sad"}}},
+		},
+		{
+			name:       "AlterSequence.Rename",
+			operations: []Operation{{Method: "AlterSequence.Rename", ObjectName: "ids", Arguments: []any{"user_ids"}}},
+			want:       []Operation{{Method: "AlterSequence.Rename", ObjectName: "user_ids", Arguments: []any{"ids"}}},
+		},
+		{
+			name: "operations are undone in reverse order",
+			operations: []Operation{
+				{Method: "CreateSchema", ObjectName: "app"},
+				{Method: "CreateEnum", SchemaName: "app", ObjectName: "mood", Arguments: []any{[]string{"happy"}}},
+				{Method: "AlterTable.AddColumn", SchemaName: "app", ObjectName: "users", Arguments: []any{ColumnDefinition{Name: "mood"}}},
+			},
+			want: []Operation{
+				{Method: "AlterTable.DropColumn", SchemaName: "app", ObjectName: "users", Arguments: []any{"mood"}},
+				{Method: "DropEnum", SchemaName: "app", ObjectName: "mood"},
+				{Method: "DropSchema", ObjectName: "app", Arguments: []any{false}},
+			},
+		},
+		{
+			name: "timeouts stay in front",
+			operations: []Operation{
+				{Method: "CreateEnum", ObjectName: "mood", Arguments: []any{[]string{"happy"}}},
+				{Method: "SetTimeouts", Arguments: []any{Timeouts{LockTimeout: 5}, true}},
+			},
+			want: []Operation{
+				{Method: "SetTimeouts", Arguments: []any{Timeouts{LockTimeout: 5}, true}},
+				{Method: "DropEnum", ObjectName: "mood"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ReverseOperations(test.operations)
+			if err != nil {
+				t.Fatalf("ReverseOperations() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ReverseOperations() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReverseOperationsIrreversible(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation Operation
+	}{
+		{name: "DropTable without a recorded table", operation: Operation{Method: "DropTable", ObjectName: "users"}},
+		{name: "DropColumn without a recorded column", operation: Operation{Method: "AlterTable.DropColumn", ObjectName: "users", Arguments: []any{"email"}}},
+		{name: "DropConstraint without a recorded constraint", operation: Operation{Method: "AlterTable.DropConstraint", ObjectName: "users", Arguments: []any{"users_email_key"}}},
+		{name: "DropIndex without a recorded index", operation: Operation{Method: "AlterTable.DropIndex", ObjectName: "users", Arguments: []any{"users_email_idx"}}},
+		{name: "AddConstraint without a name", operation: Operation{Method: "AlterTable.AddConstraint", ObjectName: "users", Arguments: []any{ConstraintDefinition{Type: ConstraintCheck}}}},
+		{name: "AddIndex without a name", operation: Operation{Method: "AlterTable.AddIndex", ObjectName: "users", Arguments: []any{IndexDefinition{ColumnNames: []string{"email"}}}}},
+		{name: "DropSchema", operation: Operation{Method: "DropSchema", ObjectName: "app", Arguments: []any{false}}},
+		{name: "AlterColumn.AlterType", operation: Operation{Method: "AlterColumn.AlterType", ObjectName: "users", ColumnName: "email", Arguments: []any{types.Text()}}},
+		{name: "AlterEnum.AddValue", operation: Operation{Method: "AlterEnum.AddValue", ObjectName: "mood", Arguments: []any{"sad"}}},
+		{name: "AlterSequence.SetOwnedBy", operation: Operation{Method: "AlterSequence.SetOwnedBy", ObjectName: "ids", Arguments: []any{"users", "id"}}},
+		{name: "RestartSequence", operation: Operation{Method: "RestartSequence", ObjectName: "ids", Arguments: []any{int64(1)}}},
+		{name: "AlterRole", operation: Operation{Method: "AlterRole", ObjectName: "reader", Arguments: []any{RoleAlteration{Name: "reader"}}}},
+		{name: "Insert", operation: Operation{Method: "Insert", ObjectName: "users", Arguments: []any{[]Row{}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ReverseOperations([]Operation{test.operation})
+			if !errors.Is(err, ErrIrreversibleOperation) {
+				t.Errorf("ReverseOperations() error = %v, want %v", err, ErrIrreversibleOperation)
+			}
+		})
+	}
+}
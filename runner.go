@@ -1,87 +1,445 @@
 package gomimi
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"time"
 )
 
 type Runner struct {
 	indicator Indicator
 	builder   Builder
+
+	linter           *Linter
+	lintFailSeverity Severity
+
+	timeouts    Timeouts
+	retryPolicy RetryPolicy
+
+	schemaVerifier SchemaVerifier
+
+	observers observers
+	logger    Logger
 }
 
-func NewRunner(indicator Indicator, builder Builder) Runner {
-	return Runner{indicator, builder}
+type RunnerOption func(runner *Runner)
+
+// WithLinter lints every pending migration before any of them is run
+// and refuses to run when a finding is at least as severe as failSeverity
+func WithLinter(linter Linter, failSeverity Severity) RunnerOption {
+	return func(runner *Runner) {
+		runner.linter = &linter
+		runner.lintFailSeverity = failSeverity
+	}
 }
 
-func (runner Runner) RunMigration(db *sql.DB, migrations ...Migration) {
-	currentMigrationName := runner.indicator.Current()
-	notFound := true
+// WithTimeouts sets the lock and statement timeouts of every migration,
+// a migration implementing TimeoutMigration can override them
+func WithTimeouts(timeouts Timeouts) RunnerOption {
+	return func(runner *Runner) {
+		runner.timeouts = timeouts
+	}
+}
+
+// WithRetryPolicy retries a migration whose transaction failed with a retryable error,
+// statements executed after the transaction was committed are never retried
+func WithRetryPolicy(policy RetryPolicy) RunnerOption {
+	return func(runner *Runner) {
+		runner.retryPolicy = policy
+	}
+}
+
+// WithObserver notifies the observer about every run, migration and statement,
+// observers are called in the order they were given
+func WithObserver(observer RunnerObserver) RunnerOption {
+	return func(runner *Runner) {
+		runner.observers = append(runner.observers, observer)
+	}
+}
+
+func NewRunner(indicator Indicator, builder Builder, options ...RunnerOption) Runner {
+	// PostgreSQL is the only dialect so far
+	runner := Runner{indicator: indicator, builder: builder, schemaVerifier: VerifySchemaPostgreSQL}
+	return runner.With(options...)
+}
+
+// With returns a copy of the runner with the options applied on top of its own
+func (runner Runner) With(options ...RunnerOption) Runner {
+	for _, option := range options {
+		option(&runner)
+	}
+	return runner
+}
+
+func pendingMigrations(currentMigrationName string, migrations []Migration) ([]Migration, error) {
 	if currentMigrationName == "" {
-		notFound = false
+		return migrations, nil
+	}
+	for index, migration := range migrations {
+		if migration.Name() == currentMigrationName {
+			return migrations[index+1:], nil
+		}
 	}
+	return nil, fmt.Errorf(`migration with name "%v" not found`, currentMigrationName)
+}
 
-	for _, migration := range migrations {
-		if !notFound {
-			// begin (for up migration)
-			runner.builder.Begin()
-			// run up migration
-			if err := migration.Up(runner.builder); err != nil {
-				// if up fail
-				// do the rollback
-				runner.builder.Rollback()
-				// begin (for down migration)
-				runner.builder.Begin()
-				// run down migration
-				if err := migration.Down(runner.builder); err != nil {
-					// if down fail
-					// do the rollback
-					runner.builder.Rollback()
-					// and panic the error
-					panic(err)
-				} else if _, err := db.Exec(runner.builder.Commit()); err != nil {
-					// if down success
-					// do commit and run the query
-					// but if query fail
-					// do rollback
-					runner.builder.Rollback()
-					// and also panic the error
-					panic(err)
-				}
-			} else if _, err := db.Exec(runner.builder.Commit()); err != nil {
-				// if up success
-				// do commit and run the query
-				// but if query fail
-				// do rollback
+func (runner Runner) lint(migrations []Migration) error {
+	if runner.linter == nil {
+		return nil
+	}
+
+	findings, err := runner.linter.Lint(migrations...)
+	if err != nil {
+		return err
+	}
+
+	failedFindings := []LintFinding{}
+	for _, finding := range findings {
+		if finding.Severity >= runner.lintFailSeverity {
+			failedFindings = append(failedFindings, finding)
+		}
+	}
+	if len(failedFindings) > 0 {
+		return fmt.Errorf("migrations failed linting:\n%v", FormatLintFindings(failedFindings))
+	}
+
+	return nil
+}
+
+func executeStatement(ctx context.Context, executor Executor, statement Statement) error {
+	if statement.Batch == nil {
+		_, err := executor.ExecContext(ctx, statement.Query, statement.Arguments...)
+		return err
+	}
+
+	// batched statements return the last processed key,
+	// which is passed to the next batch until nothing is left
+	var lastKey any
+	if err := executor.QueryRowContext(ctx, statement.Query, statement.Arguments...).Scan(&lastKey); err != nil {
+		return err
+	}
+	for lastKey != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(statement.Batch.Pause):
+		}
+		if err := executor.QueryRowContext(ctx, statement.Batch.NextQuery, lastKey).Scan(&lastKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// builderExecutor executes the statements collected by the builder
+// before every query of a FuncMigration, so both run in the order they were written
+type builderExecutor struct {
+	executor Executor
+	builder  Builder
+	run      migrationRun
+	err      error
+	executed int
+}
+
+func (executor *builderExecutor) flush(ctx context.Context) error {
+	if executor.err != nil {
+		return executor.err
+	}
+	for _, statement := range executor.builder.Statements() {
+		if err := executor.run.execute(ctx, executor.executor, statement); err != nil {
+			executor.err = err
+			return err
+		}
+		executor.executed++
+	}
+	return nil
+}
+
+func (executor *builderExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if err := executor.flush(ctx); err != nil {
+		return nil, err
+	}
+	var result sql.Result
+	err := executor.run.observe(ctx, Statement{Query: query, Arguments: args}, func(ctx context.Context) (err error) {
+		result, err = executor.executor.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+func (executor *builderExecutor) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if err := executor.flush(ctx); err != nil {
+		return nil, err
+	}
+	var rows *sql.Rows
+	err := executor.run.observe(ctx, Statement{Query: query, Arguments: args}, func(ctx context.Context) (err error) {
+		rows, err = executor.executor.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// *sql.Row cannot carry an error created outside database/sql,
+// so a failed flush is kept and reported by the runner instead
+func (executor *builderExecutor) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	executor.flush(ctx)
+	var row *sql.Row
+	executor.run.observe(ctx, Statement{Query: query, Arguments: args}, func(ctx context.Context) error {
+		row = executor.executor.QueryRowContext(ctx, query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+func (runner Runner) migrationTimeouts(migration Migration) Timeouts {
+	timeouts := runner.timeouts
+	if migration, ok := migrationValue(migration).(TimeoutMigration); ok {
+		timeouts = timeouts.override(migration.Timeouts())
+	}
+	return timeouts
+}
+
+func (runner Runner) runMigration(ctx context.Context, executor *builderExecutor, migration Migration, direction Direction) error {
+	var err error
+	if funcMigration, ok := migration.(funcMigration); ok {
+		if direction == DirectionUp {
+			err = funcMigration.migration.Up(ctx, executor, runner.builder)
+		} else {
+			err = funcMigration.migration.Down(ctx, executor, runner.builder)
+		}
+	} else {
+		if direction == DirectionUp {
+			err = migration.Up(runner.builder)
+		} else {
+			err = migration.Down(runner.builder)
+		}
+	}
+	if err == nil {
+		err = executor.flush(ctx)
+	} else if executor.err != nil {
+		// the migration most likely failed because of the statement that failed to flush
+		err = executor.err
+	}
+	return err
+}
+
+func (runner Runner) migrateTransaction(ctx context.Context, db *sql.DB, migration Migration, run migrationRun) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if timeouts := runner.migrationTimeouts(migration); timeouts != (Timeouts{}) {
+		runner.builder.SetTimeouts(timeouts, true)
+		runner.logTimeouts(run, timeouts)
+	}
+
+	err = runner.runMigration(ctx, &builderExecutor{executor: tx, builder: runner.builder, run: run}, migration, run.direction)
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		tx.Rollback()
+		run.observer.OnRollback(ctx, run.name, run.direction, err)
+	}
+	if err != nil {
+		runner.builder.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// discardConn closes the underlying connection instead of returning it to the pool,
+// because it may still carry session settings of a migration
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+}
+
+func (runner Runner) migrateWithoutTransaction(ctx context.Context, db *sql.DB, migration Migration, run migrationRun) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if builder, ok := runner.builder.(InOrderBuilder); ok {
+		builder.WriteInOrder(true)
+		defer builder.WriteInOrder(false)
+	}
+
+	// the session timeouts are set before the executor counts statements,
+	// a migration that fails on its first statement has not changed anything
+	if timeouts := runner.migrationTimeouts(migration); timeouts != (Timeouts{}) {
+		defer discardConn(conn)
+		runner.builder.SetTimeouts(timeouts, false)
+		runner.logTimeouts(run, timeouts)
+		for _, statement := range runner.builder.Statements() {
+			if err := run.execute(ctx, conn, statement); err != nil {
 				runner.builder.Rollback()
-				// begin (for down migration)
-				runner.builder.Begin()
-				// run down migration
-				if err := migration.Down(runner.builder); err != nil {
-					// if down fail
-					// do the rollback
-					runner.builder.Rollback()
-					// and panic the error
-					panic(err)
-				} else if _, err := db.Exec(runner.builder.Commit()); err != nil {
-					// if down success
-					// do commit and run the query
-					// but if query fail
-					// do rollback
-					runner.builder.Rollback()
-					// and also panic the error
-					panic(err)
-				}
+				return err
 			}
-			currentMigrationName = migration.Name()
-		} else if migration.Name() == currentMigrationName {
-			notFound = false
 		}
 	}
 
-	if notFound {
-		panic(fmt.Errorf(`migration with name "%v" not found`, currentMigrationName))
-	} else {
-		runner.indicator.Change(currentMigrationName)
+	// every statement is committed on its own,
+	// so the executed ones are counted to tell how far a failed migration got
+	executor := &builderExecutor{executor: conn, builder: runner.builder, run: run}
+	err = runner.runMigration(ctx, executor, migration, run.direction)
+	if err == nil {
+		// only a builder that cannot write in order has deferred statements left
+		for _, statement := range runner.builder.Deferred() {
+			if err = run.execute(ctx, conn, statement); err != nil {
+				break
+			}
+			executor.executed++
+		}
+	}
+	if err != nil {
+		runner.builder.Rollback()
+		if executor.executed > 0 {
+			return fmt.Errorf(`%w: migration "%v" failed after %v statement(s) were executed: %v`, ErrPartialMigration, migration.Name(), executor.executed, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (runner Runner) migrate(ctx context.Context, db *sql.DB, migration Migration, direction Direction) error {
+	ctx = runner.observers.BeforeMigration(ctx, migration.Name(), direction)
+	startedAt := time.Now()
+	err := runner.applyMigration(ctx, db, migration, migrationRun{runner.observers, migration.Name(), direction})
+	runner.observers.AfterMigration(ctx, migration.Name(), direction, time.Since(startedAt), err)
+	return err
+}
+
+func (runner Runner) applyMigration(ctx context.Context, db *sql.DB, migration Migration, run migrationRun) error {
+	if isNonTransactional(migration) {
+		return runner.migrateWithoutTransaction(ctx, db, migration, run)
+	}
+
+	attempts := 1
+	err := runner.retryPolicy.retry(ctx, func() error {
+		return runner.migrateTransaction(ctx, db, migration, run)
+	}, func(attempt int, backoff time.Duration, err error) {
+		attempts = attempt + 1
+		runner.log().Warn("migration failed to acquire a lock, retrying", "migration", run.name, "direction", run.direction.String(), "attempt", attempt, "backoff", backoff, "error", err)
+	})
+	if err != nil {
+		return err
+	}
+	// the transaction only commits once every lock it needed was acquired
+	runner.log().Info("migration acquired its locks", "migration", run.name, "direction", run.direction.String(), "attempts", attempts)
+
+	deferredStatements := runner.builder.Deferred()
+	if len(deferredStatements) == 0 {
+		return nil
+	}
+
+	// statements that cannot run inside a transaction block
+	// are executed one by one after the transaction is committed,
+	// on a single connection so session settings apply to all of them
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, statement := range deferredStatements {
+		if err := run.execute(ctx, conn, statement); err != nil {
+			discardConn(conn)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (runner Runner) RunMigration(db *sql.DB, migrations ...Migration) {
+	runner.RunMigrationContext(context.Background(), db, migrations...)
+}
+
+func (runner Runner) RunMigrationContext(ctx context.Context, db *sql.DB, migrations ...Migration) {
+	report := RunReport{}
+	ctx = runner.observers.BeforeRun(ctx, migrations)
+	startedAt := time.Now()
+	defer func() {
+		// the run fails by panicking,
+		// so the report is completed from the recovered value before panicking again
+		recovered := recover()
+		if recovered != nil {
+			if err, ok := recovered.(error); ok {
+				report.Err = err
+			} else {
+				report.Err = fmt.Errorf("%v", recovered)
+			}
+		}
+		report.Duration = time.Since(startedAt)
+		runner.observers.AfterRun(ctx, report)
+		if recovered != nil {
+			panic(recovered)
+		}
+	}()
+
+	report.Current = runner.indicator.Current()
+
+	if err := runner.checkDirty(); err != nil {
+		panic(err)
+	}
+
+	currentMigrationName := runner.adoptSquashed(report.Current, migrations)
+	report.Current = currentMigrationName
+
+	migrations, err := pendingMigrations(currentMigrationName, migrations)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := runner.lint(migrations); err != nil {
+		panic(err)
+	}
+
+	history, _ := runner.indicator.(HistoryIndicator)
+
+	for _, migration := range migrations {
+		// the migration stays dirty if the process dies before it is marked applied,
+		// so the next run refuses to continue until the state is repaired
+		if history != nil {
+			history.MarkInProgress(migration.Name())
+		}
+
+		if err := runner.migrate(ctx, db, migration, DirectionUp); err != nil {
+			if isNonTransactional(migration) {
+				// a non transactional migration cannot be undone by its down migration when it fails half way,
+				// so it is left dirty if any statement was executed
+				if history != nil && !errors.Is(err, ErrPartialMigration) {
+					history.MarkUnapplied(migration.Name())
+				}
+				report.Failed = migration.Name()
+				panic(err)
+			}
+
+			if downErr := runner.migrate(ctx, db, migration, DirectionDown); downErr != nil {
+				report.Failed = migration.Name()
+				panic(downErr)
+			}
+			// the previous migration stays current and the run stops,
+			// so the next run tries the rolled back migration again
+			if history != nil {
+				history.MarkUnapplied(migration.Name())
+			}
+			report.RolledBack = append(report.RolledBack, migration.Name())
+			panic(fmt.Errorf(`migration "%v" failed and was rolled back: %w`, migration.Name(), err))
+		}
+
+		runner.markCurrent(migration.Name())
+		report.Current = migration.Name()
+		report.Applied = append(report.Applied, migration.Name())
 	}
 }
@@ -0,0 +1,491 @@
+package gomimi
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ItsMalma/gomimi/types"
+)
+
+var errFakeStatement = errors.New("statement failed")
+
+// fakeConnector records the executed statements
+// and fails the ones matched by fail with err, queries are answered by rows
+type fakeConnector struct {
+	mu      sync.Mutex
+	queries []string
+	fail    func(query string) bool
+	err     error
+	rows    func(query string) *fakeRows
+}
+
+func (connector *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{connector}, nil
+}
+
+func (connector *fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (connector *fakeConnector) record(query string) error {
+	connector.mu.Lock()
+	defer connector.mu.Unlock()
+	connector.queries = append(connector.queries, query)
+	if connector.fail != nil && connector.fail(query) {
+		if connector.err != nil {
+			return connector.err
+		}
+		return errFakeStatement
+	}
+	return nil
+}
+
+type fakeConn struct {
+	connector *fakeConnector
+}
+
+func (conn *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (conn *fakeConn) Close() error {
+	return nil
+}
+
+func (conn *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{conn.connector}, conn.connector.record("BEGIN")
+}
+
+func (conn *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := conn.connector.record(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (conn *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := conn.connector.record(query); err != nil {
+		return nil, err
+	}
+	if conn.connector.rows != nil {
+		if rows := conn.connector.rows(query); rows != nil {
+			return rows, nil
+		}
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (rows *fakeRows) Columns() []string {
+	return rows.columns
+}
+
+func (rows *fakeRows) Close() error {
+	return nil
+}
+
+func (rows *fakeRows) Next(dest []driver.Value) error {
+	if len(rows.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, rows.values[0])
+	rows.values = rows.values[1:]
+	return nil
+}
+
+type fakeTx struct {
+	connector *fakeConnector
+}
+
+func (tx fakeTx) Commit() error {
+	return tx.connector.record("COMMIT")
+}
+
+func (tx fakeTx) Rollback() error {
+	return tx.connector.record("ROLLBACK")
+}
+
+func createTableMigration(name string, tableName string) Migration {
+	return newTestMigration(name, func(builder Builder) {
+		builder.CreateTable(tableName, []ColumnDefinition{{Name: "id", Type: types.BigInt(), PrimaryKey: true}}, nil)
+	})
+}
+
+func runMigrations(runner Runner, db *sql.DB, migrations ...Migration) (err error) {
+	defer recoverTestError(&err)
+	runner.RunMigration(db, migrations...)
+	return nil
+}
+
+func recoverTestError(err *error) {
+	if recovered := recover(); recovered != nil {
+		*err = recovered.(error)
+	}
+}
+
+func TestRunMigrationMarksCurrent(t *testing.T) {
+	connector := &fakeConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	indicator := &memoryIndicator{}
+	runner := NewRunner(indicator, NewBuilderPostgreSQL())
+
+	migrations := []Migration{createTableMigration("001", "users"), createTableMigration("002", "posts")}
+	if err := runMigrations(runner, db, migrations...); err != nil {
+		t.Fatalf("RunMigration() error = %v", err)
+	}
+
+	if indicator.current != "002" {
+		t.Errorf("current = %q, want %q", indicator.current, "002")
+	}
+	if len(indicator.history) != 2 || indicator.history[0].Dirty || indicator.history[1].Dirty {
+		t.Errorf("history = %+v, want 001 and 002 applied", indicator.history)
+	}
+}
+
+func TestRunMigrationRolledBackStopsTheRun(t *testing.T) {
+	connector := &fakeConnector{fail: func(query string) bool {
+		return strings.HasPrefix(query, "CREATE TABLE") && strings.Contains(query, `"broken"`)
+	}}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	indicator := &memoryIndicator{}
+	runner := NewRunner(indicator, NewBuilderPostgreSQL())
+
+	migrations := []Migration{
+		createTableMigration("001", "users"),
+		createTableMigration("002", "broken"),
+		createTableMigration("003", "posts"),
+	}
+	err := runMigrations(runner, db, migrations...)
+	if !errors.Is(err, errFakeStatement) {
+		t.Fatalf("RunMigration() error = %v, want %v", err, errFakeStatement)
+	}
+
+	if indicator.current != "001" {
+		t.Errorf("current = %q, want %q", indicator.current, "001")
+	}
+	if len(indicator.history) != 1 || indicator.history[0].Name != "001" {
+		t.Errorf("history = %+v, want only 001", indicator.history)
+	}
+	for _, query := range connector.queries {
+		if strings.Contains(query, `"posts"`) {
+			t.Errorf("migration 003 ran after 002 was rolled back: %v", query)
+		}
+	}
+
+	report := runner.Status(migrations...)
+	if pending := report.Pending(); len(pending) != 2 || pending[0].Name != "002" {
+		t.Errorf("Status().Pending() = %+v, want 002 and 003", pending)
+	}
+
+	connector.fail = nil
+	if err := runMigrations(runner, db, migrations...); err != nil {
+		t.Fatalf("RunMigration() error = %v", err)
+	}
+	if indicator.current != "003" {
+		t.Errorf("current = %q, want %q", indicator.current, "003")
+	}
+}
+
+func TestMigrateWithoutTransactionIgnoresTimeouts(t *testing.T) {
+	connector := &fakeConnector{fail: func(query string) bool {
+		return strings.HasPrefix(query, "CREATE TABLE")
+	}}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	indicator := &memoryIndicator{}
+	runner := NewRunner(indicator, NewBuilderPostgreSQL(), WithTimeouts(Timeouts{LockTimeout: 1000000000}))
+
+	migration := Reversible(noTransactionMigration{testMigration{name: "001", change: func(builder Builder) {
+		builder.CreateTable("users", []ColumnDefinition{{Name: "id", Type: types.BigInt()}}, nil)
+	}}})
+	err := runMigrations(runner, db, migration)
+	if !errors.Is(err, errFakeStatement) || errors.Is(err, ErrPartialMigration) {
+		t.Fatalf("RunMigration() error = %v, want a failure that is not partial", err)
+	}
+	if len(indicator.history) != 0 {
+		t.Errorf("history = %+v, want the migration unapplied", indicator.history)
+	}
+}
+
+func TestMigrateWithoutTransactionInOrder(t *testing.T) {
+	connector := &fakeConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	runner := NewRunner(&memoryIndicator{}, NewBuilderPostgreSQL())
+
+	migration := Reversible(noTransactionMigration{testMigration{name: "001", change: func(builder Builder) {
+		builder.AlterTable("users").AddIndex(IndexDefinition{Name: "users_email_idx", ColumnNames: []string{"email"}, Concurrently: true})
+		builder.Update("users", Row{"verified": true}, `"email" IS NOT NULL`)
+	}}})
+	if err := runMigrations(runner, db, migration); err != nil {
+		t.Fatalf("RunMigration() error = %v", err)
+	}
+
+	index, update := -1, -1
+	for position, query := range connector.queries {
+		switch {
+		case strings.HasPrefix(query, "CREATE INDEX CONCURRENTLY"):
+			index = position
+		case strings.HasPrefix(query, "UPDATE"):
+			update = position
+		}
+	}
+	if index == -1 || update == -1 || index > update {
+		t.Errorf("queries = %q, want the index created before the update", connector.queries)
+	}
+}
+
+type noTransactionMigration struct {
+	testMigration
+}
+
+func (migration noTransactionMigration) NoTransaction() bool {
+	return true
+}
+
+type reportObserver struct {
+	NopObserver
+	reports []RunReport
+}
+
+func (observer *reportObserver) AfterRun(ctx context.Context, report RunReport) {
+	observer.reports = append(observer.reports, report)
+}
+
+func TestRunReportCurrent(t *testing.T) {
+	connector := &fakeConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	indicator := &memoryIndicator{current: "001", history: []AppliedMigration{{Name: "001"}}}
+	observer := &reportObserver{}
+	runner := NewRunner(indicator, NewBuilderPostgreSQL(), WithObserver(observer))
+
+	migrations := []Migration{createTableMigration("001", "users"), createTableMigration("002", "posts")}
+	for range []int{0, 1} {
+		if err := runMigrations(runner, db, migrations...); err != nil {
+			t.Fatalf("RunMigration() error = %v", err)
+		}
+	}
+
+	if len(observer.reports) != 2 {
+		t.Fatalf("reports = %+v, want 2", observer.reports)
+	}
+	// the second run applies nothing but still reports the current migration
+	for index, report := range observer.reports {
+		if report.Current != "002" {
+			t.Errorf("reports[%d].Current = %q, want %q", index, report.Current, "002")
+		}
+	}
+	if len(observer.reports[1].Applied) != 0 {
+		t.Errorf("reports[1].Applied = %v, want none", observer.reports[1].Applied)
+	}
+}
+
+type seedMigration struct{}
+
+func (seedMigration) Up(ctx context.Context, executor Executor, builder Builder) error {
+	builder.CreateTable("roles", []ColumnDefinition{{Name: "name", Type: types.Text()}}, nil)
+	_, err := executor.ExecContext(ctx, `INSERT INTO "roles" ("name") VALUES ('admin');`)
+	return err
+}
+
+func (seedMigration) Down(ctx context.Context, executor Executor, builder Builder) error {
+	builder.DropTable("roles")
+	return nil
+}
+
+func (seedMigration) Name() string {
+	return "001"
+}
+
+func TestFuncMigrationInTransaction(t *testing.T) {
+	connector := &fakeConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	runner := NewRunner(&memoryIndicator{}, NewBuilderPostgreSQL())
+
+	if err := runMigrations(runner, db, Func(seedMigration{})); err != nil {
+		t.Fatalf("RunMigration() error = %v", err)
+	}
+
+	// the built table is written before the query that needs it
+	want := []string{"BEGIN", `CREATE TABLE IF NOT EXISTS "roles" ("name" TEXT NOT NULL);`, `INSERT INTO "roles" ("name") VALUES ('admin');`, "COMMIT"}
+	if !reflect.DeepEqual(connector.queries, want) {
+		t.Errorf("queries = %q, want %q", connector.queries, want)
+	}
+}
+
+func TestRunMigrationConcurrentIndexAfterCommit(t *testing.T) {
+	connector := &fakeConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	runner := NewRunner(&memoryIndicator{}, NewBuilderPostgreSQL())
+
+	migration := newTestMigration("001", func(builder Builder) {
+		builder.AlterTable("users").AddIndex(IndexDefinition{Name: "users_email_idx", ColumnNames: []string{"email"}, Concurrently: true})
+	})
+	if err := runMigrations(runner, db, migration); err != nil {
+		t.Fatalf("RunMigration() error = %v", err)
+	}
+
+	// CREATE INDEX CONCURRENTLY cannot run inside a transaction block
+	want := []string{"BEGIN", "COMMIT", `CREATE INDEX CONCURRENTLY IF NOT EXISTS "users_email_idx" ON "users" ("email");`}
+	if !reflect.DeepEqual(connector.queries, want) {
+		t.Errorf("queries = %q, want %q", connector.queries, want)
+	}
+}
+
+func TestRunMigrationBatchBackfill(t *testing.T) {
+	lastKeys := []driver.Value{int64(500), int64(1000), nil}
+	connector := &fakeConnector{}
+	connector.rows = func(query string) *fakeRows {
+		key := lastKeys[0]
+		lastKeys = lastKeys[1:]
+		return &fakeRows{columns: []string{"max"}, values: [][]driver.Value{{key}}}
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	runner := NewRunner(&memoryIndicator{}, NewBuilderPostgreSQL())
+
+	migration := newTestMigration("001", func(builder Builder) {
+		builder.BatchBackfill(BackfillDefinition{TableName: "users", PrimaryKeyColumnName: "id", Set: map[string]string{"name": `lower("name")`}, BatchSize: 500})
+	})
+	if err := runMigrations(runner, db, migration); err != nil {
+		t.Fatalf("RunMigration() error = %v", err)
+	}
+
+	// the backfill runs after the commit until a batch updates nothing
+	batches := connector.queries[2:]
+	if len(batches) != 3 || strings.Contains(batches[0], "$1") || !strings.Contains(batches[1], `"id" > $1`) || batches[1] != batches[2] {
+		t.Errorf("queries = %q, want the first batch followed by 2 next batches", connector.queries)
+	}
+	if len(lastKeys) != 0 {
+		t.Errorf("%v batch(es) were not run", len(lastKeys))
+	}
+}
+
+type noTransactionSeedMigration struct {
+	seedMigration
+}
+
+func (noTransactionSeedMigration) NoTransaction() bool {
+	return true
+}
+
+func TestFuncMigrationWithoutTransaction(t *testing.T) {
+	connector := &fakeConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	runner := NewRunner(&memoryIndicator{}, NewBuilderPostgreSQL())
+
+	if err := runMigrations(runner, db, Func(noTransactionSeedMigration{})); err != nil {
+		t.Fatalf("RunMigration() error = %v", err)
+	}
+
+	want := []string{`CREATE TABLE IF NOT EXISTS "roles" ("name" TEXT NOT NULL);`, `INSERT INTO "roles" ("name") VALUES ('admin');`}
+	if !reflect.DeepEqual(connector.queries, want) {
+		t.Errorf("queries = %q, want %q", connector.queries, want)
+	}
+}
+
+func TestFuncMigrationFailureRollsBack(t *testing.T) {
+	connector := &fakeConnector{fail: func(query string) bool {
+		return strings.HasPrefix(query, "INSERT")
+	}}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	indicator := &memoryIndicator{}
+	runner := NewRunner(indicator, NewBuilderPostgreSQL())
+
+	err := runMigrations(runner, db, Func(seedMigration{}))
+	if !errors.Is(err, errFakeStatement) {
+		t.Fatalf("RunMigration() error = %v, want %v", err, errFakeStatement)
+	}
+	if len(connector.queries) < 4 || connector.queries[3] != "ROLLBACK" {
+		t.Errorf("queries = %q, want the transaction rolled back after the failed query", connector.queries)
+	}
+	if len(indicator.history) != 0 {
+		t.Errorf("history = %+v, want the migration unapplied", indicator.history)
+	}
+}
+
+type timeoutMigration struct {
+	testMigration
+	timeouts Timeouts
+}
+
+func (migration timeoutMigration) Timeouts() Timeouts {
+	return migration.timeouts
+}
+
+// lockTimeoutError is what lib/pq and pgx return when lock_timeout is exceeded
+type lockTimeoutError struct{}
+
+func (lockTimeoutError) Error() string {
+	return "canceling statement due to lock timeout"
+}
+
+func (lockTimeoutError) SQLState() string {
+	return "55P03"
+}
+
+func TestRunMigrationSetsLocalTimeouts(t *testing.T) {
+	connector := &fakeConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	runner := NewRunner(&memoryIndicator{}, NewBuilderPostgreSQL(), WithTimeouts(Timeouts{LockTimeout: time.Second, StatementTimeout: time.Minute}))
+
+	migration := Reversible(timeoutMigration{testMigration{name: "001", change: func(builder Builder) {
+		builder.DropTable("sessions")
+	}}, Timeouts{LockTimeout: 10 * time.Second}})
+	if err := runMigrations(runner, db, migration); err != nil {
+		t.Fatalf("RunMigration() error = %v", err)
+	}
+
+	// the migration overrides the lock timeout and keeps the statement timeout of the runner
+	want := []string{"BEGIN", `SET LOCAL lock_timeout = '10000ms';`, `SET LOCAL statement_timeout = '60000ms';`, `DROP TABLE IF EXISTS "sessions";`, "COMMIT"}
+	if !reflect.DeepEqual(connector.queries, want) {
+		t.Errorf("queries = %q, want %q", connector.queries, want)
+	}
+}
+
+func TestRunMigrationRetriesLockTimeouts(t *testing.T) {
+	failures := 2
+	connector := &fakeConnector{err: lockTimeoutError{}, fail: func(query string) bool {
+		if strings.HasPrefix(query, "CREATE TABLE") && failures > 0 {
+			failures--
+			return true
+		}
+		return false
+	}}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	indicator := &memoryIndicator{}
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	runner := NewRunner(indicator, NewBuilderPostgreSQL(), WithRetryPolicy(policy))
+
+	if err := runMigrations(runner, db, createTableMigration("001", "users")); err != nil {
+		t.Fatalf("RunMigration() error = %v", err)
+	}
+	if failures != 0 {
+		t.Errorf("failures = %v, want every lock timeout retried", failures)
+	}
+	if indicator.current != "001" {
+		t.Errorf("current = %q, want %q", indicator.current, "001")
+	}
+}
@@ -0,0 +1,588 @@
+package gomimi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ItsMalma/gomimi/types"
+)
+
+type TableSchema struct {
+	SchemaName            string
+	Name                  string
+	Columns               []ColumnDefinition
+	Constraints           []ConstraintDefinition
+	Indexes               []IndexDefinition
+	Options               []TableOption
+	Comment               string
+	PartitionOfTableName  string
+	PartitionBounds       PartitionBounds
+	RowLevelSecurity      bool
+	ForceRowLevelSecurity bool
+	// triggers, policies, statistics targets and attached partitions,
+	// in the order they were recorded
+	Operations []Operation
+}
+
+func (table *TableSchema) columnIndex(name string) int {
+	for index, column := range table.Columns {
+		if column.Name == name {
+			return index
+		}
+	}
+	return -1
+}
+
+func (table *TableSchema) constraintIndex(name string) int {
+	for index, constraint := range table.Constraints {
+		if constraint.Name == name {
+			return index
+		}
+	}
+	return -1
+}
+
+func (table *TableSchema) indexIndex(name string) int {
+	for index, tableIndex := range table.Indexes {
+		if tableIndex.Name == name {
+			return index
+		}
+	}
+	return -1
+}
+
+func (table *TableSchema) removeOperations(remove func(operation Operation) bool) bool {
+	operations := []Operation{}
+	removed := false
+	for _, operation := range table.Operations {
+		if remove(operation) {
+			removed = true
+		} else {
+			operations = append(operations, operation)
+		}
+	}
+	table.Operations = operations
+	return removed
+}
+
+func containsName(names []string, name string) bool {
+	for _, otherName := range names {
+		if otherName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func renameName(names []string, oldName string, newName string) []string {
+	renamedNames := make([]string, len(names))
+	for index, name := range names {
+		if name == oldName {
+			name = newName
+		}
+		renamedNames[index] = name
+	}
+	return renamedNames
+}
+
+func (table *TableSchema) dropColumn(name string) {
+	index := table.columnIndex(name)
+	table.Columns = append(table.Columns[:index], table.Columns[index+1:]...)
+
+	// like the database, constraints and indexes using the column are dropped with it
+	constraints := []ConstraintDefinition{}
+	for _, constraint := range table.Constraints {
+		if !containsName(constraint.ColumnNames, name) {
+			constraints = append(constraints, constraint)
+		}
+	}
+	table.Constraints = constraints
+
+	indexes := []IndexDefinition{}
+	for _, index := range table.Indexes {
+		used := containsName(index.ColumnNames, name)
+		for _, column := range index.Columns {
+			used = used || column.Name == name
+		}
+		if !used {
+			indexes = append(indexes, index)
+		}
+	}
+	table.Indexes = indexes
+
+	table.removeOperations(func(operation Operation) bool {
+		return operation.ColumnName == name
+	})
+}
+
+func (table *TableSchema) renameColumn(oldName string, newName string) {
+	table.Columns[table.columnIndex(oldName)].Name = newName
+
+	for index := range table.Constraints {
+		table.Constraints[index].ColumnNames = renameName(table.Constraints[index].ColumnNames, oldName, newName)
+	}
+	for index := range table.Indexes {
+		table.Indexes[index].ColumnNames = renameName(table.Indexes[index].ColumnNames, oldName, newName)
+		columns := append([]IndexColumnDefinition{}, table.Indexes[index].Columns...)
+		for columnIndex := range columns {
+			if columns[columnIndex].Name == oldName {
+				columns[columnIndex].Name = newName
+			}
+		}
+		table.Indexes[index].Columns = columns
+	}
+	for index := range table.Operations {
+		if table.Operations[index].ColumnName == oldName {
+			table.Operations[index].ColumnName = newName
+		}
+	}
+}
+
+// references tells whether a reference made by the table points at the other table,
+// a reference without a schema is in the schema of the table making it
+func (table *TableSchema) references(schemaName string, tableName string, otherTable *TableSchema) bool {
+	if schemaName == "" {
+		schemaName = table.SchemaName
+	}
+	return schemaName == otherTable.SchemaName && tableName == otherTable.Name
+}
+
+// string literals are matched so the names inside them are left alone
+var queryIdentifierPattern = regexp.MustCompile(`'(?:[^']|'')*'|"(?:[^"]|"")*"|[\pL_][\pL\pN_$]*`)
+
+// renameQueryIdentifier replaces the name wherever the query uses it as an identifier,
+// an unquoted name matches regardless of case like it does in the database
+func renameQueryIdentifier(query string, oldName string, newName string) string {
+	quotedNewName := `"` + strings.ReplaceAll(newName, `"`, `""`) + `"`
+	return queryIdentifierPattern.ReplaceAllStringFunc(query, func(token string) string {
+		switch {
+		case token == `"`+strings.ReplaceAll(oldName, `"`, `""`)+`"`:
+			return quotedNewName
+		case !strings.HasPrefix(token, `"`) && !strings.HasPrefix(token, "'") && strings.EqualFold(token, oldName):
+			return quotedNewName
+		}
+		return token
+	})
+}
+
+// Schema is the net result of replaying recorded operations,
+// tables are kept as their final definition while other objects keep the operations that created them
+type Schema struct {
+	Tables     []*TableSchema
+	Operations []Operation
+}
+
+func (schema *Schema) table(schemaName string, name string) *TableSchema {
+	for _, table := range schema.Tables {
+		if table.SchemaName == schemaName && table.Name == name {
+			return table
+		}
+	}
+	return nil
+}
+
+func (schema *Schema) existingTable(operation Operation) (*TableSchema, error) {
+	table := schema.table(operation.SchemaName, operation.ObjectName)
+	if table == nil {
+		return nil, fmt.Errorf(`%v: table "%v" was not created by the replayed migrations`, operation.Method, operation.ObjectName)
+	}
+	return table, nil
+}
+
+func (schema *Schema) existingColumn(operation Operation, columnName string) (*TableSchema, int, error) {
+	table, err := schema.existingTable(operation)
+	if err != nil {
+		return nil, 0, err
+	}
+	index := table.columnIndex(columnName)
+	if index < 0 {
+		return nil, 0, fmt.Errorf(`%v: column "%v" of table "%v" was not created by the replayed migrations`, operation.Method, columnName, operation.ObjectName)
+	}
+	return table, index, nil
+}
+
+// ColumnsOf returns the columns of the table,
+// a partition has the columns of the table it is a partition of
+func (schema *Schema) ColumnsOf(table *TableSchema) []ColumnDefinition {
+	for table.PartitionOfTableName != "" {
+		parent := schema.table(table.SchemaName, table.PartitionOfTableName)
+		if parent == nil {
+			break
+		}
+		table = parent
+	}
+	return table.Columns
+}
+
+// the operations removed when an object is dropped, by the method that drops it
+var dropMethodPrefixes = map[string][]string{
+	"DropSchema":           {"CreateSchema"},
+	"DropExtension":        {"CreateExtension"},
+	"DropView":             {"CreateView", "CreateOrReplaceView"},
+	"DropMaterializedView": {"CreateMaterializedView"},
+	"DropEnum":             {"CreateEnum", "AlterEnum."},
+	"DropSequence":         {"CreateSequence", "AlterSequence.", "RestartSequence"},
+	"DropFunction":         {"CreateFunction", "CreateOrReplaceFunction"},
+	"DropProcedure":        {"CreateProcedure", "CreateOrReplaceProcedure"},
+	"DropRole":             {"CreateRole", "AlterRole"},
+}
+
+func (schema *Schema) removeOperations(schemaName string, objectName string, methodPrefixes []string) bool {
+	operations := []Operation{}
+	removed := false
+	for _, operation := range schema.Operations {
+		matched := false
+		if operation.SchemaName == schemaName && operation.ObjectName == objectName {
+			for _, methodPrefix := range methodPrefixes {
+				matched = matched || strings.HasPrefix(operation.Method, methodPrefix)
+			}
+		}
+		if matched {
+			removed = true
+		} else {
+			operations = append(operations, operation)
+		}
+	}
+	schema.Operations = operations
+	return removed
+}
+
+// renameTableReferences points the objects that name the table at its new name,
+// the database follows the table itself but the recorded operations only know it by name
+func (schema *Schema) renameTableReferences(schemaName string, oldName string, newName string) {
+	for index, operation := range schema.Operations {
+		if operation.SchemaName != schemaName {
+			continue
+		}
+		arguments := operation.Arguments
+		switch operation.Method {
+		case "Grant", "Revoke":
+			grant := arguments[0].(GrantDefinition)
+			if grant.ObjectType == GrantTable {
+				grant.ObjectNames = renameName(grant.ObjectNames, oldName, newName)
+				schema.Operations[index] = operation.with(operation.Method, operation.ObjectName, grant)
+			}
+		case "CreateView", "CreateOrReplaceView":
+			view := arguments[0].(ViewDefinition)
+			view.Query = renameQueryIdentifier(view.Query, oldName, newName)
+			schema.Operations[index] = operation.with(operation.Method, operation.ObjectName, view)
+		case "CreateMaterializedView":
+			view := arguments[0].(MaterializedViewDefinition)
+			view.Query = renameQueryIdentifier(view.Query, oldName, newName)
+			schema.Operations[index] = operation.with(operation.Method, operation.ObjectName, view)
+		case "CreateSequence":
+			sequence := arguments[0].(SequenceDefinition)
+			if sequence.OwnedByTableName == oldName {
+				sequence.OwnedByTableName = newName
+				schema.Operations[index] = operation.with(operation.Method, operation.ObjectName, sequence)
+			}
+		case "AlterSequence.SetOwnedBy":
+			if arguments[0] == oldName {
+				schema.Operations[index] = operation.with(operation.Method, operation.ObjectName, newName, arguments[1])
+			}
+		}
+	}
+}
+
+func (schema *Schema) Apply(operation Operation) error {
+	arguments := operation.Arguments
+	switch operation.Method {
+	case "SetTimeouts", "TruncateTable", "RefreshMaterializedView", "Insert", "Update", "Delete", "BatchBackfill":
+		// these change data or settings, not the schema
+	case "CreateSchema", "CreateExtension", "CreateView", "CreateMaterializedView", "CreateEnum", "CreateSequence",
+		"RestartSequence", "CreateFunction", "CreateProcedure", "Grant", "Revoke", "CreateRole", "AlterRole":
+		schema.Operations = append(schema.Operations, operation)
+	case "CreateOrReplaceView":
+		schema.removeOperations(operation.SchemaName, operation.ObjectName, dropMethodPrefixes["DropView"])
+		schema.Operations = append(schema.Operations, operation)
+	case "CreateOrReplaceFunction":
+		schema.removeOperations(operation.SchemaName, operation.ObjectName, dropMethodPrefixes["DropFunction"])
+		schema.Operations = append(schema.Operations, operation)
+	case "CreateOrReplaceProcedure":
+		schema.removeOperations(operation.SchemaName, operation.ObjectName, dropMethodPrefixes["DropProcedure"])
+		schema.Operations = append(schema.Operations, operation)
+	case "DropExtension", "DropView", "DropMaterializedView", "DropEnum", "DropSequence", "DropFunction", "DropProcedure", "DropRole":
+		if !schema.removeOperations(operation.SchemaName, operation.ObjectName, dropMethodPrefixes[operation.Method]) {
+			return fmt.Errorf(`%v: "%v" was not created by the replayed migrations`, operation.Method, operation.ObjectName)
+		}
+	case "DropSchema":
+		if !schema.removeOperations("", operation.ObjectName, dropMethodPrefixes[operation.Method]) {
+			return fmt.Errorf(`%v: "%v" was not created by the replayed migrations`, operation.Method, operation.ObjectName)
+		}
+		tables := []*TableSchema{}
+		for _, table := range schema.Tables {
+			if table.SchemaName != operation.ObjectName {
+				tables = append(tables, table)
+			}
+		}
+		schema.Tables = tables
+		operations := []Operation{}
+		for _, schemaOperation := range schema.Operations {
+			if schemaOperation.SchemaName != operation.ObjectName {
+				operations = append(operations, schemaOperation)
+			}
+		}
+		schema.Operations = operations
+	case "CreateTable":
+		if schema.table(operation.SchemaName, operation.ObjectName) != nil {
+			return fmt.Errorf(`%v: table "%v" already exists`, operation.Method, operation.ObjectName)
+		}
+		schema.Tables = append(schema.Tables, &TableSchema{
+			SchemaName:  operation.SchemaName,
+			Name:        operation.ObjectName,
+			Columns:     append([]ColumnDefinition{}, arguments[0].([]ColumnDefinition)...),
+			Constraints: append([]ConstraintDefinition{}, arguments[1].([]ConstraintDefinition)...),
+			Options:     arguments[2].([]TableOption),
+		})
+	case "CreatePartitionOf":
+		if schema.table(operation.SchemaName, operation.ObjectName) != nil {
+			return fmt.Errorf(`%v: table "%v" already exists`, operation.Method, operation.ObjectName)
+		}
+		schema.Tables = append(schema.Tables, &TableSchema{
+			SchemaName:           operation.SchemaName,
+			Name:                 operation.ObjectName,
+			PartitionOfTableName: arguments[0].(string),
+			PartitionBounds:      arguments[1].(PartitionBounds),
+		})
+	case "DropTable":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		tables := []*TableSchema{}
+		for _, otherTable := range schema.Tables {
+			if otherTable != table {
+				tables = append(tables, otherTable)
+			}
+		}
+		schema.Tables = tables
+	case "AlterTable.Rename":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		newTableName := arguments[0].(string)
+		// references follow the table like they do in the database
+		for _, otherTable := range schema.Tables {
+			if otherTable.PartitionOfTableName == table.Name {
+				otherTable.PartitionOfTableName = newTableName
+			}
+			for index := range otherTable.Columns {
+				column := &otherTable.Columns[index]
+				if otherTable.references(column.ReferenceSchemaName, column.ReferenceTableName, table) {
+					column.ReferenceTableName = newTableName
+				}
+			}
+			for index := range otherTable.Constraints {
+				constraint := &otherTable.Constraints[index]
+				if otherTable.references(constraint.ReferenceSchemaName, constraint.ReferenceTableName, table) {
+					constraint.ReferenceTableName = newTableName
+				}
+			}
+		}
+		schema.renameTableReferences(table.SchemaName, table.Name, newTableName)
+		table.Name = newTableName
+	case "AlterTable.AddColumn":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		table.Columns = append(table.Columns, arguments[0].(ColumnDefinition))
+	case "AlterTable.DropColumn":
+		table, _, err := schema.existingColumn(operation, arguments[0].(string))
+		if err != nil {
+			return err
+		}
+		table.dropColumn(arguments[0].(string))
+	case "AlterTable.RenameColumn":
+		table, _, err := schema.existingColumn(operation, arguments[0].(string))
+		if err != nil {
+			return err
+		}
+		table.renameColumn(arguments[0].(string), arguments[1].(string))
+	case "AlterTable.AddConstraint":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		table.Constraints = append(table.Constraints, arguments[0].(ConstraintDefinition))
+	case "AlterTable.DropConstraint", "AlterTable.RenameConstraint", "AlterTable.ValidateConstraint":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		index := table.constraintIndex(arguments[0].(string))
+		if index < 0 {
+			return fmt.Errorf(`%v: constraint "%v" of table "%v" was not created by the replayed migrations`, operation.Method, arguments[0], operation.ObjectName)
+		}
+		switch operation.Method {
+		case "AlterTable.DropConstraint":
+			table.Constraints = append(table.Constraints[:index], table.Constraints[index+1:]...)
+		case "AlterTable.RenameConstraint":
+			table.Constraints[index].Name = arguments[1].(string)
+		case "AlterTable.ValidateConstraint":
+			table.Constraints[index].NotValid = false
+		}
+	case "AlterTable.AddIndex":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		index := arguments[0].(IndexDefinition)
+		// nothing can be locked on a table that is created in the same migration
+		index.Concurrently = false
+		table.Indexes = append(table.Indexes, index)
+	case "AlterTable.DropIndex", "AlterTable.RenameIndex":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		index := table.indexIndex(arguments[0].(string))
+		if index < 0 {
+			return fmt.Errorf(`%v: index "%v" of table "%v" was not created by the replayed migrations`, operation.Method, arguments[0], operation.ObjectName)
+		}
+		if operation.Method == "AlterTable.DropIndex" {
+			table.Indexes = append(table.Indexes[:index], table.Indexes[index+1:]...)
+		} else {
+			table.Indexes[index].Name = arguments[1].(string)
+		}
+	case "AlterTable.AttachPartition", "AlterTable.CreateTrigger", "AlterTable.CreatePolicy":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		table.Operations = append(table.Operations, operation)
+	case "AlterTable.DetachPartition":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		partitionName := arguments[0].(string)
+		if table.removeOperations(func(tableOperation Operation) bool {
+			return tableOperation.Method == "AlterTable.AttachPartition" && tableOperation.Arguments[0] == partitionName
+		}) {
+			break
+		}
+		partition := schema.table(operation.SchemaName, partitionName)
+		if partition == nil || partition.PartitionOfTableName != table.Name {
+			return fmt.Errorf(`%v: partition "%v" of table "%v" was not created by the replayed migrations`, operation.Method, partitionName, operation.ObjectName)
+		}
+		// a detached partition keeps the columns it had as a partition
+		partition.Columns = append([]ColumnDefinition{}, schema.ColumnsOf(partition)...)
+		partition.PartitionOfTableName = ""
+		partition.PartitionBounds = PartitionBounds{}
+	case "AlterTable.DropTrigger", "AlterTable.DropPolicy":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		name := arguments[0].(string)
+		if !table.removeOperations(func(tableOperation Operation) bool {
+			switch tableOperation.Method {
+			case "AlterTable.CreateTrigger":
+				return operation.Method == "AlterTable.DropTrigger" && tableOperation.Arguments[0].(TriggerDefinition).Name == name
+			case "AlterTable.CreatePolicy":
+				return operation.Method == "AlterTable.DropPolicy" && tableOperation.Arguments[0].(PolicyDefinition).Name == name
+			}
+			return false
+		}) {
+			return fmt.Errorf(`%v: "%v" of table "%v" was not created by the replayed migrations`, operation.Method, name, operation.ObjectName)
+		}
+	case "AlterTable.SetComment":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		table.Comment = arguments[0].(string)
+	case "AlterTable.SetColumnComment":
+		table, index, err := schema.existingColumn(operation, arguments[0].(string))
+		if err != nil {
+			return err
+		}
+		table.Columns[index].Comment = arguments[1].(string)
+	case "AlterTable.EnableRowLevelSecurity", "AlterTable.DisableRowLevelSecurity":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		table.RowLevelSecurity = operation.Method == "AlterTable.EnableRowLevelSecurity"
+	case "AlterTable.ForceRowLevelSecurity", "AlterTable.NoForceRowLevelSecurity":
+		table, err := schema.existingTable(operation)
+		if err != nil {
+			return err
+		}
+		table.ForceRowLevelSecurity = operation.Method == "AlterTable.ForceRowLevelSecurity"
+	case "AlterColumn.SetStatistics":
+		table, _, err := schema.existingColumn(operation, operation.ColumnName)
+		if err != nil {
+			return err
+		}
+		table.removeOperations(func(tableOperation Operation) bool {
+			return tableOperation.Method == operation.Method && tableOperation.ColumnName == operation.ColumnName
+		})
+		table.Operations = append(table.Operations, operation)
+	case "AlterColumn.AlterType", "AlterColumn.AlterDefault", "AlterColumn.DropDefault", "AlterColumn.SetNullable",
+		"AlterColumn.DropNullable", "AlterColumn.SetAutoIncrement", "AlterColumn.DropAutoIncrement",
+		"AlterColumn.SetCollation", "AlterColumn.DropExpression", "AlterColumn.SetStorage", "AlterColumn.SetCompression":
+		table, index, err := schema.existingColumn(operation, operation.ColumnName)
+		if err != nil {
+			return err
+		}
+		column := &table.Columns[index]
+		switch operation.Method {
+		case "AlterColumn.AlterType":
+			column.Type = arguments[0].(types.Type)
+		case "AlterColumn.AlterDefault":
+			column.Default = arguments[0].(string)
+		case "AlterColumn.DropDefault":
+			column.Default = ""
+		case "AlterColumn.SetNullable":
+			column.Nullable = true
+		case "AlterColumn.DropNullable":
+			column.Nullable = false
+		case "AlterColumn.SetAutoIncrement":
+			column.AutoIncrement = true
+		case "AlterColumn.DropAutoIncrement":
+			column.AutoIncrement = false
+		case "AlterColumn.SetCollation":
+			column.Type = arguments[0].(types.Type)
+			column.Collation = arguments[1].(string)
+		case "AlterColumn.DropExpression":
+			column.GeneratedExpression = ""
+		case "AlterColumn.SetStorage":
+			column.Storage = arguments[0].(ColumnStorage)
+		case "AlterColumn.SetCompression":
+			column.Compression = arguments[0].(string)
+		}
+	default:
+		if strings.HasPrefix(operation.Method, "AlterEnum.") || strings.HasPrefix(operation.Method, "AlterSequence.") {
+			schema.Operations = append(schema.Operations, operation)
+			break
+		}
+		return fmt.Errorf(`%v: operation cannot be replayed into a schema`, operation.Method)
+	}
+	return nil
+}
+
+// SchemaOf replays the up migrations through a Recorder,
+// function migrations need a database connection and cannot be replayed
+func SchemaOf(migrations ...Migration) (*Schema, error) {
+	schema := &Schema{}
+	for _, migration := range migrations {
+		if _, ok := migration.(funcMigration); ok {
+			return nil, fmt.Errorf(`migration "%v" is a function migration and cannot be replayed`, migration.Name())
+		}
+
+		recorder := NewRecorder()
+		if err := migration.Up(recorder); err != nil {
+			return nil, fmt.Errorf(`migration "%v": %w`, migration.Name(), err)
+		}
+		for _, operation := range recorder.Operations() {
+			if err := schema.Apply(operation); err != nil {
+				return nil, fmt.Errorf(`migration "%v": %w`, migration.Name(), err)
+			}
+		}
+	}
+	return schema, nil
+}
@@ -0,0 +1,324 @@
+package gomimi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SquashMigration is implemented by a migration that replaces others,
+// a database where the last replaced migration is current treats it as applied
+type SquashMigration interface {
+	Replaces() []string
+}
+
+type Squashed struct {
+	MigrationName string
+	ReplacedNames []string
+	Operations    []Operation
+}
+
+func (squashed Squashed) Up(builder Builder) error {
+	for _, operation := range squashed.Operations {
+		operation.Apply(builder)
+	}
+	return nil
+}
+
+// Down drops what Up created in the reverse order,
+// changes made to the created objects go away with them so they are not reversed one by one
+func (squashed Squashed) Down(builder Builder) error {
+	for index := len(squashed.Operations) - 1; index >= 0; index-- {
+		operation := squashed.Operations[index]
+		// objects created with or replace are dropped like any other
+		operation.Method = strings.Replace(operation.Method, "CreateOrReplace", "Create", 1)
+		switch operation.Method {
+		case "CreateSchema", "CreateExtension", "CreateRole", "CreateEnum", "CreateTable", "CreatePartitionOf",
+			"CreateView", "CreateMaterializedView", "CreateFunction", "CreateProcedure", "Grant", "Revoke",
+			"AlterTable.AttachPartition", "AlterTable.CreateTrigger", "AlterTable.CreatePolicy":
+			inverseOperations, err := operation.inverse(nil)
+			if err != nil {
+				return fmt.Errorf(`migration "%v": %w`, squashed.MigrationName, err)
+			}
+			for _, inverseOperation := range inverseOperations {
+				inverseOperation.Apply(builder)
+			}
+		case "CreateSequence":
+			builder.DropSequence(finalName(squashed.Operations[index+1:], "AlterSequence.Rename", operation.ObjectName))
+		case "AlterSequence.SetOwnedBy":
+			// a sequence owned by a column would be dropped with its table before DropSequence
+			builder.AlterSequence(operation.ObjectName).DropOwnedBy()
+		case "AlterTable.AddConstraint":
+			// foreign keys are the only constraints that keep another table from being dropped
+			constraint := operation.Arguments[0].(ConstraintDefinition)
+			if constraint.Type == ConstraintForeignKey {
+				builder.AlterTable(operation.ObjectName).DropConstraint(foreignKeyName(operation.ObjectName, constraint))
+			}
+		}
+	}
+	return nil
+}
+
+func (squashed Squashed) Name() string {
+	return squashed.MigrationName
+}
+
+func (squashed Squashed) Replaces() []string {
+	return squashed.ReplacedNames
+}
+
+// SQL renders the squashed migration with the given builder,
+// statements that run outside a transaction come last
+func (squashed Squashed) SQL(builder Builder) string {
+	squashed.Up(builder)
+
+	queries := []string{}
+	for _, statement := range append(builder.Statements(), builder.Deferred()...) {
+		queries = append(queries, statement.Query)
+	}
+	return strings.Join(queries, "\n\n")
+}
+
+// Squash replays the migrations into their net schema
+// and returns a single migration that creates it directly,
+// migrations that change data or need a database connection cannot be squashed
+func Squash(name string, migrations ...Migration) (Squashed, error) {
+	schema := &Schema{}
+	replacedNames := []string{}
+	for _, migration := range migrations {
+		if _, ok := migration.(funcMigration); ok {
+			return Squashed{}, fmt.Errorf(`migration "%v" is a function migration and cannot be squashed`, migration.Name())
+		}
+
+		recorder := NewRecorder()
+		if err := migration.Up(recorder); err != nil {
+			return Squashed{}, fmt.Errorf(`migration "%v": %w`, migration.Name(), err)
+		}
+		for _, operation := range recorder.Operations() {
+			switch operation.Method {
+			case "Insert", "Update", "Delete", "BatchBackfill", "TruncateTable":
+				return Squashed{}, fmt.Errorf(`migration "%v" changes data and cannot be squashed`, migration.Name())
+			}
+			if err := schema.Apply(operation); err != nil {
+				return Squashed{}, fmt.Errorf(`migration "%v": %w`, migration.Name(), err)
+			}
+		}
+
+		replacedNames = append(replacedNames, migration.Name())
+	}
+
+	return Squashed{
+		MigrationName: name,
+		ReplacedNames: replacedNames,
+		Operations:    schema.CreateOperations(),
+	}, nil
+}
+
+// finalName follows the renames of an object through the operations
+func finalName(operations []Operation, method string, name string) string {
+	for _, operation := range operations {
+		if operation.Method == method && operation.ObjectName == name {
+			name = operation.Arguments[0].(string)
+		}
+	}
+	return name
+}
+
+func (schema *Schema) finalName(index int, method string, name string) string {
+	return finalName(schema.Operations[index+1:], method, name)
+}
+
+func (schema *Schema) operationsWith(prefixes ...string) []Operation {
+	operations := []Operation{}
+	for _, operation := range schema.Operations {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(operation.Method, prefix) {
+				operations = append(operations, operation)
+				break
+			}
+		}
+	}
+	return operations
+}
+
+func (table *TableSchema) operation(method string, arguments ...any) Operation {
+	return Operation{
+		Method:     method,
+		SchemaName: table.SchemaName,
+		ObjectName: table.Name,
+		Arguments:  arguments,
+	}
+}
+
+// foreignKeyName is the name PostgreSQL gives a foreign key declared without one
+func foreignKeyName(tableName string, constraint ConstraintDefinition) string {
+	if constraint.Name != "" {
+		return constraint.Name
+	}
+	return tableName + "_" + strings.Join(constraint.ColumnNames, "_") + "_fkey"
+}
+
+// foreignKey names an unnamed foreign key,
+// so Down can drop it before the table it references
+func (table *TableSchema) foreignKey(constraint ConstraintDefinition) ConstraintDefinition {
+	constraint.Name = foreignKeyName(table.Name, constraint)
+	return constraint
+}
+
+// CreateOperations returns the operations that create the schema from nothing,
+// ordered so every object exists before something depends on it
+func (schema *Schema) CreateOperations() []Operation {
+	operations := schema.operationsWith("CreateSchema", "CreateExtension", "CreateRole", "AlterRole", "CreateEnum", "AlterEnum.")
+
+	// sequences can only be owned by a column once its table exists
+	ownerships := []Operation{}
+	for index, operation := range schema.Operations {
+		switch {
+		case operation.Method == "CreateSequence":
+			sequence := operation.Arguments[0].(SequenceDefinition)
+			if sequence.OwnedByTableName != "" {
+				ownership := operation.with("AlterSequence.SetOwnedBy", schema.finalName(index, "AlterSequence.Rename", operation.ObjectName), sequence.OwnedByTableName, sequence.OwnedByColumnName)
+				ownerships = append(ownerships, ownership)
+				sequence.OwnedByTableName = ""
+				sequence.OwnedByColumnName = ""
+			}
+			operations = append(operations, operation.with(operation.Method, operation.ObjectName, sequence))
+		case operation.Method == "AlterSequence.SetOwnedBy" || operation.Method == "AlterSequence.DropOwnedBy":
+			ownership := operation.with(operation.Method, schema.finalName(index, "AlterSequence.Rename", operation.ObjectName), operation.Arguments...)
+			ownerships = append(ownerships, ownership)
+		case strings.HasPrefix(operation.Method, "AlterSequence.") || operation.Method == "RestartSequence":
+			operations = append(operations, operation)
+		}
+	}
+
+	// foreign keys, and constraints of partitions, are added once every table exists
+	addedConstraints := []Operation{}
+	for _, table := range schema.Tables {
+		if table.PartitionOfTableName != "" {
+			continue
+		}
+		columns := make([]ColumnDefinition, len(table.Columns))
+		for index, column := range table.Columns {
+			if column.Reference {
+				addedConstraints = append(addedConstraints, table.operation("AlterTable.AddConstraint", table.foreignKey(ConstraintDefinition{
+					Type:                 ConstraintForeignKey,
+					ColumnNames:          []string{column.Name},
+					ReferenceSchemaName:  column.ReferenceSchemaName,
+					ReferenceTableName:   column.ReferenceTableName,
+					ReferenceColumnNames: column.ReferenceColumnNames,
+				})))
+				column.Reference = false
+				column.ReferenceSchemaName = ""
+				column.ReferenceTableName = ""
+				column.ReferenceColumnNames = nil
+			}
+			columns[index] = column
+		}
+		constraints := []ConstraintDefinition{}
+		for _, constraint := range table.Constraints {
+			if constraint.Type == ConstraintForeignKey {
+				addedConstraints = append(addedConstraints, table.operation("AlterTable.AddConstraint", table.foreignKey(constraint)))
+			} else {
+				constraints = append(constraints, constraint)
+			}
+		}
+		operations = append(operations, table.operation("CreateTable", columns, constraints, table.Options))
+	}
+	for _, table := range schema.Tables {
+		if table.PartitionOfTableName != "" {
+			operations = append(operations, table.operation("CreatePartitionOf", table.PartitionOfTableName, table.PartitionBounds))
+			for _, constraint := range table.Constraints {
+				if constraint.Type == ConstraintForeignKey {
+					constraint = table.foreignKey(constraint)
+				}
+				addedConstraints = append(addedConstraints, table.operation("AlterTable.AddConstraint", constraint))
+			}
+		}
+	}
+	for _, table := range schema.Tables {
+		for _, index := range table.Indexes {
+			operations = append(operations, table.operation("AlterTable.AddIndex", index))
+		}
+	}
+	operations = append(operations, addedConstraints...)
+	for _, table := range schema.Tables {
+		for _, operation := range table.Operations {
+			if operation.Method == "AlterTable.AttachPartition" {
+				operations = append(operations, table.operation(operation.Method, operation.Arguments...))
+			}
+		}
+	}
+	operations = append(operations, ownerships...)
+
+	operations = append(operations, schema.operationsWith("CreateFunction", "CreateOrReplaceFunction", "CreateProcedure", "CreateOrReplaceProcedure")...)
+
+	for _, table := range schema.Tables {
+		if table.Comment != "" {
+			operations = append(operations, table.operation("AlterTable.SetComment", table.Comment))
+		}
+		if table.RowLevelSecurity {
+			operations = append(operations, table.operation("AlterTable.EnableRowLevelSecurity"))
+		}
+		if table.ForceRowLevelSecurity {
+			operations = append(operations, table.operation("AlterTable.ForceRowLevelSecurity"))
+		}
+		for _, operation := range table.Operations {
+			if operation.Method != "AlterTable.AttachPartition" {
+				tableOperation := table.operation(operation.Method, operation.Arguments...)
+				tableOperation.ColumnName = operation.ColumnName
+				operations = append(operations, tableOperation)
+			}
+		}
+	}
+
+	operations = append(operations, schema.operationsWith("CreateView", "CreateOrReplaceView", "CreateMaterializedView")...)
+	operations = append(operations, schema.operationsWith("Grant", "Revoke")...)
+
+	return operations
+}
+
+// squashedCurrent returns the migration that replaced the current one,
+// only a database where the whole replaced range was applied can treat it as applied
+func squashedCurrent(currentMigrationName string, migrations []Migration) (Migration, error) {
+	if currentMigrationName == "" {
+		return nil, nil
+	}
+	for _, migration := range migrations {
+		if migration.Name() == currentMigrationName {
+			return nil, nil
+		}
+	}
+	for _, migration := range migrations {
+		squash, ok := migrationValue(migration).(SquashMigration)
+		if !ok {
+			continue
+		}
+		replacedNames := squash.Replaces()
+		for index, replacedName := range replacedNames {
+			if replacedName != currentMigrationName {
+				continue
+			}
+			if index != len(replacedNames)-1 {
+				return nil, fmt.Errorf(`migration "%v" was squashed into "%v", apply the migrations up to "%v" before upgrading`, currentMigrationName, migration.Name(), replacedNames[len(replacedNames)-1])
+			}
+			return migration, nil
+		}
+	}
+	return nil, nil
+}
+
+// adoptSquashed records a squashed migration as applied
+// when the database has applied every migration it replaced
+func (runner Runner) adoptSquashed(currentMigrationName string, migrations []Migration) string {
+	squashed, err := squashedCurrent(currentMigrationName, migrations)
+	if err != nil {
+		panic(err)
+	}
+	if squashed == nil {
+		return currentMigrationName
+	}
+
+	runner.markCurrent(squashed.Name())
+	runner.log().Info("squashed migration adopted", "migration", squashed.Name(), "replaced", currentMigrationName)
+
+	return squashed.Name()
+}
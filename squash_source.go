@@ -0,0 +1,266 @@
+package gomimi
+
+import (
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const gomimiPackagePath = "github.com/ItsMalma/gomimi"
+
+var tableOptionType = reflect.TypeOf(TableOption(nil))
+
+// goSourceWriter writes values as Go composite literals,
+// remembering the packages the literals need
+type goSourceWriter struct {
+	source  strings.Builder
+	imports map[string]string
+}
+
+func (writer *goSourceWriter) write(text string) {
+	writer.source.WriteString(text)
+}
+
+func (writer *goSourceWriter) typeName(valueType reflect.Type) (string, error) {
+	if valueType.Name() != "" {
+		if valueType.PkgPath() == "" {
+			return valueType.Name(), nil
+		}
+		packageName := valueType.PkgPath()[strings.LastIndex(valueType.PkgPath(), "/")+1:]
+		writer.imports[valueType.PkgPath()] = packageName
+		return packageName + "." + valueType.Name(), nil
+	}
+
+	switch valueType.Kind() {
+	case reflect.Slice:
+		elementName, err := writer.typeName(valueType.Elem())
+		return "[]" + elementName, err
+	case reflect.Pointer:
+		elementName, err := writer.typeName(valueType.Elem())
+		return "*" + elementName, err
+	case reflect.Map:
+		keyName, err := writer.typeName(valueType.Key())
+		if err != nil {
+			return "", err
+		}
+		elementName, err := writer.typeName(valueType.Elem())
+		return "map[" + keyName + "]" + elementName, err
+	case reflect.Interface:
+		if valueType.NumMethod() == 0 {
+			return "any", nil
+		}
+	}
+	return "", fmt.Errorf("type %v cannot be written as Go source", valueType)
+}
+
+func (writer *goSourceWriter) writeTableOptions(options []TableOption) {
+	tableOptions := TableOptions{}
+	for _, option := range options {
+		option(&tableOptions)
+	}
+
+	writer.imports[gomimiPackagePath] = "gomimi"
+	calls := []string{}
+	if tableOptions.Temporary {
+		calls = append(calls, "gomimi.Temporary()")
+	}
+	if tableOptions.Unlogged {
+		calls = append(calls, "gomimi.Unlogged()")
+	}
+	if len(tableOptions.InheritsTableNames) > 0 {
+		calls = append(calls, fmt.Sprintf("gomimi.Inherits(%v)", quoteNames(tableOptions.InheritsTableNames)))
+	}
+	if tableOptions.PartitionStrategy != PartitionNone {
+		arguments := []string{fmt.Sprintf("gomimi.PartitionStrategy(%d)", tableOptions.PartitionStrategy)}
+		if len(tableOptions.PartitionColumnNames) > 0 {
+			arguments = append(arguments, quoteNames(tableOptions.PartitionColumnNames))
+		}
+		calls = append(calls, fmt.Sprintf("gomimi.PartitionBy(%v)", strings.Join(arguments, ", ")))
+	}
+	storageParameterNames := make([]string, 0, len(tableOptions.StorageParameters))
+	for name := range tableOptions.StorageParameters {
+		storageParameterNames = append(storageParameterNames, name)
+	}
+	sort.Strings(storageParameterNames)
+	for _, name := range storageParameterNames {
+		calls = append(calls, fmt.Sprintf("gomimi.WithStorageParameter(%q, %q)", name, tableOptions.StorageParameters[name]))
+	}
+	if tableOptions.Tablespace != "" {
+		calls = append(calls, fmt.Sprintf("gomimi.InTablespace(%q)", tableOptions.Tablespace))
+	}
+
+	if len(calls) == 0 {
+		writer.write("[]gomimi.TableOption(nil)")
+		return
+	}
+	writer.write("[]gomimi.TableOption{" + strings.Join(calls, ", ") + "}")
+}
+
+func quoteNames(names []string) string {
+	quotedNames := make([]string, len(names))
+	for index, name := range names {
+		quotedNames[index] = strconv.Quote(name)
+	}
+	return strings.Join(quotedNames, ", ")
+}
+
+func (writer *goSourceWriter) writeValue(value reflect.Value, valueType reflect.Type) error {
+	if valueType == reflect.SliceOf(tableOptionType) {
+		writer.writeTableOptions(value.Interface().([]TableOption))
+		return nil
+	}
+
+	switch valueType.Kind() {
+	case reflect.Interface:
+		if value.IsNil() {
+			writer.write("nil")
+			return nil
+		}
+		// the concrete type has to be spelled out unless Go would infer it
+		element := value.Elem()
+		switch element.Kind() {
+		case reflect.String, reflect.Bool, reflect.Int:
+			if element.Type().Name() == element.Kind().String() {
+				return writer.writeValue(element, element.Type())
+			}
+		}
+		switch element.Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Map, reflect.Pointer:
+			return writer.writeValue(element, element.Type())
+		}
+		typeName, err := writer.typeName(element.Type())
+		if err != nil {
+			return err
+		}
+		writer.write(typeName + "(")
+		if err := writer.writeValue(element, element.Type()); err != nil {
+			return err
+		}
+		writer.write(")")
+		return nil
+	case reflect.String:
+		if valueType.Name() != "string" {
+			typeName, err := writer.typeName(valueType)
+			if err != nil {
+				return err
+			}
+			writer.write(typeName + "(" + strconv.Quote(value.String()) + ")")
+			return nil
+		}
+		writer.write(strconv.Quote(value.String()))
+	case reflect.Bool:
+		writer.write(strconv.FormatBool(value.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writer.write(strconv.FormatInt(value.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		writer.write(strconv.FormatUint(value.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		writer.write(strconv.FormatFloat(value.Float(), 'g', -1, 64))
+	case reflect.Pointer:
+		if value.IsNil() {
+			// a typed nil, so an interface holding it keeps its type
+			typeName, err := writer.typeName(valueType)
+			if err != nil {
+				return err
+			}
+			writer.write("(" + typeName + ")(nil)")
+			return nil
+		}
+		writer.write("&")
+		return writer.writeValue(value.Elem(), valueType.Elem())
+	case reflect.Slice:
+		typeName, err := writer.typeName(valueType)
+		if err != nil {
+			return err
+		}
+		if value.IsNil() {
+			writer.write(typeName + "(nil)")
+			return nil
+		}
+		writer.write(typeName + "{\n")
+		for index := 0; index < value.Len(); index++ {
+			if err := writer.writeValue(value.Index(index), valueType.Elem()); err != nil {
+				return err
+			}
+			writer.write(",\n")
+		}
+		writer.write("}")
+	case reflect.Map:
+		typeName, err := writer.typeName(valueType)
+		if err != nil {
+			return err
+		}
+		if value.IsNil() {
+			writer.write(typeName + "(nil)")
+			return nil
+		}
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		writer.write(typeName + "{\n")
+		for _, key := range keys {
+			if err := writer.writeValue(key, valueType.Key()); err != nil {
+				return err
+			}
+			writer.write(": ")
+			if err := writer.writeValue(value.MapIndex(key), valueType.Elem()); err != nil {
+				return err
+			}
+			writer.write(",\n")
+		}
+		writer.write("}")
+	case reflect.Struct:
+		typeName, err := writer.typeName(valueType)
+		if err != nil {
+			return err
+		}
+		writer.write(typeName + "{\n")
+		for index := 0; index < valueType.NumField(); index++ {
+			if value.Field(index).IsZero() {
+				continue
+			}
+			writer.write(valueType.Field(index).Name + ": ")
+			if err := writer.writeValue(value.Field(index), valueType.Field(index).Type); err != nil {
+				return err
+			}
+			writer.write(",\n")
+		}
+		writer.write("}")
+	default:
+		return fmt.Errorf("type %v cannot be written as Go source", valueType)
+	}
+
+	return nil
+}
+
+// GoSource renders the squashed migration as a Go file declaring it as a variable,
+// the variable can be passed to the Runner in place of the migrations it replaces
+func (squashed Squashed) GoSource(packageName string, variableName string) ([]byte, error) {
+	writer := &goSourceWriter{imports: map[string]string{gomimiPackagePath: "gomimi"}}
+	if err := writer.writeValue(reflect.ValueOf(squashed), reflect.TypeOf(squashed)); err != nil {
+		return nil, err
+	}
+	literal := writer.source.String()
+
+	importPaths := make([]string, 0, len(writer.imports))
+	for importPath := range writer.imports {
+		importPaths = append(importPaths, importPath)
+	}
+	sort.Strings(importPaths)
+
+	source := strings.Builder{}
+	source.WriteString("// Code generated by gomimi squash. DO NOT EDIT.\n\n")
+	source.WriteString(fmt.Sprintf("package %v\n\n", packageName))
+	source.WriteString("import (\n")
+	for _, importPath := range importPaths {
+		source.WriteString(strconv.Quote(importPath) + "\n")
+	}
+	source.WriteString(")\n\n")
+	source.WriteString(fmt.Sprintf("var %v = %v\n", variableName, literal))
+
+	return format.Source([]byte(source.String()))
+}
@@ -0,0 +1,368 @@
+package gomimi
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ItsMalma/gomimi/types"
+)
+
+func operationSummaries(operations []Operation) []string {
+	summaries := make([]string, len(operations))
+	for index, operation := range operations {
+		summaries[index] = operation.Method + " " + operation.ObjectName
+	}
+	return summaries
+}
+
+func squashTestMigrations() []Migration {
+	return []Migration{
+		newTestMigration("001", func(builder Builder) {
+			builder.CreateRole(RoleDefinition{Name: "reader"})
+			builder.CreateEnum("status", "active")
+			builder.CreateTable("users", []ColumnDefinition{
+				{Name: "id", Type: types.BigInt(), PrimaryKey: true},
+				{Name: "status", Type: types.Raw("status")},
+			}, nil)
+		}),
+		newTestMigration("002", func(builder Builder) {
+			builder.AlterEnum("status").AddValue("inactive")
+			builder.CreateTable("posts", []ColumnDefinition{
+				{Name: "id", Type: types.BigInt(), PrimaryKey: true},
+				{Name: "user_id", Type: types.BigInt()},
+			}, []ConstraintDefinition{{
+				Name:                 "posts_user_id_fkey",
+				Type:                 ConstraintForeignKey,
+				ColumnNames:          []string{"user_id"},
+				ReferenceTableName:   "users",
+				ReferenceColumnNames: []string{"id"},
+			}})
+			builder.CreateSequence(SequenceDefinition{Name: "post_numbers", OwnedByTableName: "posts", OwnedByColumnName: "id"})
+		}),
+		newTestMigration("003", func(builder Builder) {
+			builder.AlterRole(RoleAlteration{Name: "reader", Login: RoleAttributeEnabled})
+			builder.AlterSequence("post_numbers").SetIncrement(2).Rename("post_counters")
+			builder.RestartSequence("post_counters", 10)
+			builder.CreateView(ViewDefinition{Name: "active_users", Query: `SELECT "id" FROM "users" WHERE "status" = 'active'`})
+			builder.Grant(GrantDefinition{Privileges: []string{"SELECT"}, ObjectType: GrantTable, ObjectNames: []string{"active_users"}, RoleNames: []string{"reader"}})
+		}),
+	}
+}
+
+func TestSquashOperationOrder(t *testing.T) {
+	squashed, err := Squash("squashed", squashTestMigrations()...)
+	if err != nil {
+		t.Fatalf("Squash() error = %v", err)
+	}
+
+	want := []string{
+		"CreateRole reader",
+		"CreateEnum status",
+		"AlterEnum.AddValue status",
+		"AlterRole reader",
+		"CreateSequence post_numbers",
+		"AlterSequence.SetIncrement post_numbers",
+		"AlterSequence.Rename post_numbers",
+		"RestartSequence post_counters",
+		"CreateTable users",
+		"CreateTable posts",
+		"AlterTable.AddConstraint posts",
+		"AlterSequence.SetOwnedBy post_counters",
+		"CreateView active_users",
+		"Grant ",
+	}
+	if got := operationSummaries(squashed.Operations); !reflect.DeepEqual(got, want) {
+		t.Errorf("Operations = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(squashed.ReplacedNames, []string{"001", "002", "003"}) {
+		t.Errorf("ReplacedNames = %v, want 001, 002 and 003", squashed.ReplacedNames)
+	}
+
+	// the sequence is created before its table and owned once the table exists
+	sequence := squashed.Operations[4].Arguments[0].(SequenceDefinition)
+	if sequence.OwnedByTableName != "" || sequence.OwnedByColumnName != "" {
+		t.Errorf("CreateSequence owned by %v.%v, want no owner", sequence.OwnedByTableName, sequence.OwnedByColumnName)
+	}
+	if got := squashed.Operations[11].Arguments; !reflect.DeepEqual(got, []any{"posts", "id"}) {
+		t.Errorf("SetOwnedBy arguments = %v, want posts and id", got)
+	}
+}
+
+func TestSquashedDown(t *testing.T) {
+	squashed, err := Squash("squashed", squashTestMigrations()...)
+	if err != nil {
+		t.Fatalf("Squash() error = %v", err)
+	}
+
+	recorder := NewRecorder()
+	if err := squashed.Down(recorder); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+
+	want := []string{
+		"Revoke ",
+		"DropView active_users",
+		"AlterSequence.DropOwnedBy post_counters",
+		"AlterTable.DropConstraint posts",
+		"DropTable posts",
+		"DropTable users",
+		"DropSequence post_counters",
+		"DropEnum status",
+		"DropRole reader",
+	}
+	if got := operationSummaries(recorder.Operations()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Down() = %v, want %v", got, want)
+	}
+}
+
+func TestSquashRenamedTable(t *testing.T) {
+	squashed, err := Squash("squashed",
+		newTestMigration("001", func(builder Builder) {
+			builder.CreateTable("users", []ColumnDefinition{{Name: "id", Type: types.BigInt(), PrimaryKey: true}}, nil)
+			builder.CreateSequence(SequenceDefinition{Name: "user_numbers", OwnedByTableName: "users", OwnedByColumnName: "id"})
+			builder.CreateSequence(SequenceDefinition{Name: "user_codes"})
+			builder.AlterSequence("user_codes").SetOwnedBy("users", "id")
+			builder.CreateView(ViewDefinition{Name: "user_ids", Query: `SELECT "id" FROM "users"`})
+			builder.CreateMaterializedView(MaterializedViewDefinition{Name: "user_counts", Query: `SELECT count(*) FROM public.users`})
+			builder.Grant(GrantDefinition{Privileges: []string{"SELECT"}, ObjectType: GrantTable, ObjectNames: []string{"users", "user_ids"}, RoleNames: []string{"reader"}})
+		}),
+		newTestMigration("002", func(builder Builder) {
+			builder.AlterTable("users").Rename("accounts")
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Squash() error = %v", err)
+	}
+
+	want := []Operation{
+		{Method: "CreateSequence", ObjectName: "user_numbers", Arguments: []any{SequenceDefinition{Name: "user_numbers"}}},
+		{Method: "CreateSequence", ObjectName: "user_codes", Arguments: []any{SequenceDefinition{Name: "user_codes"}}},
+		{Method: "CreateTable", ObjectName: "accounts", Arguments: []any{
+			[]ColumnDefinition{{Name: "id", Type: types.BigInt(), PrimaryKey: true}},
+			[]ConstraintDefinition{},
+			[]TableOption(nil),
+		}},
+		{Method: "AlterSequence.SetOwnedBy", ObjectName: "user_numbers", Arguments: []any{"accounts", "id"}},
+		{Method: "AlterSequence.SetOwnedBy", ObjectName: "user_codes", Arguments: []any{"accounts", "id"}},
+		{Method: "CreateView", ObjectName: "user_ids", Arguments: []any{ViewDefinition{Name: "user_ids", Query: `SELECT "id" FROM "accounts"`}}},
+		{Method: "CreateMaterializedView", ObjectName: "user_counts", Arguments: []any{MaterializedViewDefinition{Name: "user_counts", Query: `SELECT count(*) FROM public."accounts"`}}},
+		{Method: "Grant", Arguments: []any{GrantDefinition{Privileges: []string{"SELECT"}, ObjectType: GrantTable, ObjectNames: []string{"accounts", "user_ids"}, RoleNames: []string{"reader"}}}},
+	}
+	if !reflect.DeepEqual(squashed.Operations, want) {
+		t.Errorf("Operations = %#v, want %#v", squashed.Operations, want)
+	}
+}
+
+func TestRenameQueryIdentifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"quoted", `SELECT * FROM "users"`, `SELECT * FROM "accounts"`},
+		{"unquoted", `SELECT users.id FROM Users`, `SELECT "accounts".id FROM "accounts"`},
+		{"qualified", `SELECT * FROM "public"."users"`, `SELECT * FROM "public"."accounts"`},
+		{"other names", `SELECT * FROM users_archive JOIN "Users" USING (id)`, `SELECT * FROM users_archive JOIN "Users" USING (id)`},
+		{"string literals", `SELECT 'users' FROM users`, `SELECT 'users' FROM "accounts"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := renameQueryIdentifier(test.query, "users", "accounts"); got != test.want {
+				t.Errorf("renameQueryIdentifier() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+const roundTripMain = `package main
+
+import (
+	"fmt"
+
+	"github.com/ItsMalma/gomimi"
+)
+
+func main() {
+	fmt.Print(squashed.SQL(gomimi.NewBuilderPostgreSQL()))
+}
+`
+
+// runGoSource compiles the generated source into a program printing its SQL,
+// so the source has to type check and its operations have to apply
+func runGoSource(t *testing.T, source []byte) string {
+	t.Helper()
+
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	// inside the module so the program can import gomimi, ./... skips directories starting with _
+	directory, err := os.MkdirTemp(".", "_squash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+	if err := os.WriteFile(filepath.Join(directory, "squashed.go"), source, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(directory, "main.go"), []byte(roundTripMain), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := exec.Command(goPath, "run", "./"+directory).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run error = %v\n%s\n%s", err, output, source)
+	}
+	return string(output)
+}
+
+func TestSquashedGoSource(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles the generated source")
+	}
+
+	partitions := newTestMigration("001", func(builder Builder) {
+		builder.CreateTable("events", []ColumnDefinition{
+			{Name: "id", Type: types.BigInt()},
+			{Name: "created_at", Type: types.Timestamp(true)},
+		}, nil, PartitionBy(PartitionRange, "created_at"), WithStorageParameter("fillfactor", "90"))
+		builder.CreatePartitionOf("events_2026", "events", PartitionBounds{From: []string{"'2026-01-01'"}, To: []string{"'2027-01-01'"}})
+		builder.CreateTable("events_default", []ColumnDefinition{
+			{Name: "id", Type: types.BigInt()},
+			{Name: "created_at", Type: types.Timestamp(true)},
+		}, nil)
+		builder.AlterTable("events").AttachPartition("events_default", PartitionBounds{Default: true})
+	})
+	emptyValues := newTestMigration("001", func(builder Builder) {
+		builder.CreateEnum("flags")
+		builder.CreateFunction(FunctionDefinition{Name: "now_utc", Returns: types.Timestamp(false), Language: "sql", Body: "SELECT now() AT TIME ZONE 'utc'"})
+		builder.CreateTable("tags", []ColumnDefinition{{Name: "names", Type: types.Array(types.Text())}}, nil)
+		builder.AlterTable("tags").AlterColumn("names", func(alterColumnBuilder AlterColumnBuilder) {
+			alterColumnBuilder.SetStorage(ColumnStorageExternal)
+		})
+		builder.RestartSequence("tags_id_seq", 10)
+	})
+
+	tests := []struct {
+		name       string
+		migrations []Migration
+	}{
+		{"enums, sequences and roles", squashTestMigrations()},
+		{"partitions", []Migration{partitions}},
+		{"empty values", []Migration{emptyValues}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			squashed, err := Squash("squashed", test.migrations...)
+			if err != nil {
+				t.Fatalf("Squash() error = %v", err)
+			}
+			source, err := squashed.GoSource("main", "squashed")
+			if err != nil {
+				t.Fatalf("GoSource() error = %v", err)
+			}
+
+			want := squashed.SQL(NewBuilderPostgreSQL())
+			if got := runGoSource(t, source); got != want {
+				t.Errorf("regenerated SQL = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSquashForeignKeys(t *testing.T) {
+	squashed, err := Squash("squashed",
+		newTestMigration("001", func(builder Builder) {
+			builder.CreateTable("users", []ColumnDefinition{{Name: "id", Type: types.BigInt(), PrimaryKey: true}}, nil)
+		}),
+		newTestMigration("002", func(builder Builder) {
+			builder.CreateTable("teams", []ColumnDefinition{
+				{Name: "id", Type: types.BigInt(), PrimaryKey: true},
+				{Name: "owner_id", Type: types.BigInt()},
+			}, []ConstraintDefinition{{Type: ConstraintForeignKey, ColumnNames: []string{"owner_id"}, ReferenceTableName: "users", ReferenceColumnNames: []string{"id"}}})
+		}),
+		newTestMigration("003", func(builder Builder) {
+			builder.AlterTable("users").AddColumn(ColumnDefinition{
+				Name:                 "team_id",
+				Type:                 types.BigInt(),
+				Reference:            true,
+				ReferenceTableName:   "teams",
+				ReferenceColumnNames: []string{"id"},
+			})
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Squash() error = %v", err)
+	}
+
+	want := []Operation{
+		{Method: "CreateTable", ObjectName: "users", Arguments: []any{
+			[]ColumnDefinition{{Name: "id", Type: types.BigInt(), PrimaryKey: true}, {Name: "team_id", Type: types.BigInt()}},
+			[]ConstraintDefinition{},
+			[]TableOption(nil),
+		}},
+		{Method: "CreateTable", ObjectName: "teams", Arguments: []any{
+			[]ColumnDefinition{{Name: "id", Type: types.BigInt(), PrimaryKey: true}, {Name: "owner_id", Type: types.BigInt()}},
+			[]ConstraintDefinition{},
+			[]TableOption(nil),
+		}},
+		{Method: "AlterTable.AddConstraint", ObjectName: "users", Arguments: []any{ConstraintDefinition{
+			Name:                 "users_team_id_fkey",
+			Type:                 ConstraintForeignKey,
+			ColumnNames:          []string{"team_id"},
+			ReferenceTableName:   "teams",
+			ReferenceColumnNames: []string{"id"},
+		}}},
+		{Method: "AlterTable.AddConstraint", ObjectName: "teams", Arguments: []any{ConstraintDefinition{
+			Name:                 "teams_owner_id_fkey",
+			Type:                 ConstraintForeignKey,
+			ColumnNames:          []string{"owner_id"},
+			ReferenceTableName:   "users",
+			ReferenceColumnNames: []string{"id"},
+		}}},
+	}
+	if !reflect.DeepEqual(squashed.Operations, want) {
+		t.Errorf("Operations = %#v, want %#v", squashed.Operations, want)
+	}
+
+	recorder := NewRecorder()
+	if err := squashed.Down(recorder); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	wantDown := []Operation{
+		{Method: "AlterTable.DropConstraint", ObjectName: "teams", Arguments: []any{"teams_owner_id_fkey"}},
+		{Method: "AlterTable.DropConstraint", ObjectName: "users", Arguments: []any{"users_team_id_fkey"}},
+		{Method: "DropTable", ObjectName: "teams"},
+		{Method: "DropTable", ObjectName: "users"},
+	}
+	if got := recorder.Operations(); !reflect.DeepEqual(got, wantDown) {
+		t.Errorf("Down() = %#v, want %#v", got, wantDown)
+	}
+}
+
+func TestSquashRejectsDataChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(builder Builder)
+	}{
+		{"Insert", func(builder Builder) { builder.Insert("users", []Row{{"id": 1}}) }},
+		{"Update", func(builder Builder) { builder.Update("users", Row{"id": 2}, `"id" = $1`, 1) }},
+		{"Delete", func(builder Builder) { builder.Delete("users", `"id" = $1`, 1) }},
+		{"TruncateTable", func(builder Builder) { builder.TruncateTable("users") }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Squash("squashed", createTableMigration("001", "users"), newTestMigration("002", test.change))
+			if err == nil || !strings.Contains(err.Error(), "changes data") {
+				t.Errorf("Squash() error = %v, want the data change rejected", err)
+			}
+		})
+	}
+}
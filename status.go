@@ -0,0 +1,135 @@
+package gomimi
+
+import "time"
+
+type MigrationState uint8
+
+const (
+	MigrationPending MigrationState = iota
+	MigrationApplied
+	MigrationMissing
+)
+
+func (state MigrationState) String() string {
+	switch state {
+	case MigrationApplied:
+		return "applied"
+	case MigrationMissing:
+		return "missing"
+	default:
+		return "pending"
+	}
+}
+
+func (state MigrationState) MarshalText() ([]byte, error) {
+	return []byte(state.String()), nil
+}
+
+type MigrationStatus struct {
+	Name      string
+	State     MigrationState
+	AppliedAt time.Time
+	Dirty     bool
+}
+
+type StatusReport struct {
+	Current    string
+	Dirty      bool
+	Migrations []MigrationStatus
+}
+
+func (report StatusReport) filter(state MigrationState) []MigrationStatus {
+	statuses := []MigrationStatus{}
+	for _, status := range report.Migrations {
+		if status.State == state {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+func (report StatusReport) Applied() []MigrationStatus {
+	return report.filter(MigrationApplied)
+}
+
+func (report StatusReport) Pending() []MigrationStatus {
+	return report.filter(MigrationPending)
+}
+
+func (report StatusReport) Missing() []MigrationStatus {
+	return report.filter(MigrationMissing)
+}
+
+// Status compares the given migrations with the ones recorded by the indicator,
+// migrations up to the current one count as applied even without a recorded history
+func (runner Runner) Status(migrations ...Migration) StatusReport {
+	report := StatusReport{Current: runner.indicator.Current()}
+
+	history := []AppliedMigration{}
+	if indicator, ok := runner.indicator.(HistoryIndicator); ok {
+		history = indicator.Applied()
+	}
+	appliedMigrations := map[string]AppliedMigration{}
+	for _, appliedMigration := range history {
+		appliedMigrations[appliedMigration.Name] = appliedMigration
+	}
+
+	// migrations replaced by a squashed migration are neither missing
+	// nor make the squashed migration pending when the last of them is current
+	knownNames := map[string]bool{}
+	for _, migration := range migrations {
+		knownNames[migration.Name()] = true
+		if squash, ok := migrationValue(migration).(SquashMigration); ok {
+			for _, replacedName := range squash.Replaces() {
+				knownNames[replacedName] = true
+			}
+		}
+	}
+
+	currentMigrationName := report.Current
+	if squashed, err := squashedCurrent(currentMigrationName, migrations); err == nil && squashed != nil {
+		currentMigrationName = squashed.Name()
+	}
+
+	appliedThroughCurrent := currentMigrationName != "" && migrationIndex(currentMigrationName, migrations) >= 0
+	for _, migration := range migrations {
+		name := migration.Name()
+
+		status := MigrationStatus{Name: name, State: MigrationPending}
+		if appliedMigration, ok := appliedMigrations[name]; ok {
+			status.AppliedAt = appliedMigration.AppliedAt
+			status.Dirty = appliedMigration.Dirty
+			if !appliedMigration.Dirty {
+				status.State = MigrationApplied
+			}
+		} else if appliedThroughCurrent {
+			status.State = MigrationApplied
+		}
+		if name == currentMigrationName {
+			appliedThroughCurrent = false
+		}
+
+		report.Dirty = report.Dirty || status.Dirty
+		report.Migrations = append(report.Migrations, status)
+	}
+
+	if report.Current != "" && !knownNames[report.Current] {
+		if _, ok := appliedMigrations[report.Current]; !ok {
+			history = append(history, AppliedMigration{Name: report.Current})
+		}
+	}
+	for _, appliedMigration := range history {
+		if knownNames[appliedMigration.Name] {
+			continue
+		}
+		report.Dirty = report.Dirty || appliedMigration.Dirty
+		report.Migrations = append(report.Migrations, MigrationStatus{
+			Name:      appliedMigration.Name,
+			State:     MigrationMissing,
+			AppliedAt: appliedMigration.AppliedAt,
+			Dirty:     appliedMigration.Dirty,
+		})
+	}
+
+	return report
+}
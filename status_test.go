@@ -0,0 +1,170 @@
+package gomimi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStatus(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	migrations := []Migration{newTestMigration("001", nil), newTestMigration("002", nil), newTestMigration("003", nil)}
+
+	tests := []struct {
+		name       string
+		indicator  Indicator
+		migrations []Migration
+		want       StatusReport
+	}{
+		{
+			name:       "nothing applied",
+			indicator:  &memoryIndicator{},
+			migrations: migrations,
+			want: StatusReport{
+				Migrations: []MigrationStatus{
+					{Name: "001", State: MigrationPending},
+					{Name: "002", State: MigrationPending},
+					{Name: "003", State: MigrationPending},
+				},
+			},
+		},
+		{
+			name: "applied from the history",
+			indicator: &memoryIndicator{current: "002", history: []AppliedMigration{
+				{Name: "001", AppliedAt: appliedAt},
+				{Name: "002", AppliedAt: appliedAt},
+			}},
+			migrations: migrations,
+			want: StatusReport{
+				Current: "002",
+				Migrations: []MigrationStatus{
+					{Name: "001", State: MigrationApplied, AppliedAt: appliedAt},
+					{Name: "002", State: MigrationApplied, AppliedAt: appliedAt},
+					{Name: "003", State: MigrationPending},
+				},
+			},
+		},
+		{
+			name:       "applied up to the current migration without a history",
+			indicator:  &memoryIndicator{current: "002"},
+			migrations: migrations,
+			want: StatusReport{
+				Current: "002",
+				Migrations: []MigrationStatus{
+					{Name: "001", State: MigrationApplied},
+					{Name: "002", State: MigrationApplied},
+					{Name: "003", State: MigrationPending},
+				},
+			},
+		},
+		{
+			name: "dirty migration",
+			indicator: &memoryIndicator{current: "001", history: []AppliedMigration{
+				{Name: "001", AppliedAt: appliedAt},
+				{Name: "002", AppliedAt: appliedAt, Dirty: true},
+			}},
+			migrations: migrations,
+			want: StatusReport{
+				Current: "001",
+				Dirty:   true,
+				Migrations: []MigrationStatus{
+					{Name: "001", State: MigrationApplied, AppliedAt: appliedAt},
+					{Name: "002", State: MigrationPending, AppliedAt: appliedAt, Dirty: true},
+					{Name: "003", State: MigrationPending},
+				},
+			},
+		},
+		{
+			name: "history row without a migration",
+			indicator: &memoryIndicator{current: "002", history: []AppliedMigration{
+				{Name: "000_removed", AppliedAt: appliedAt},
+				{Name: "001", AppliedAt: appliedAt},
+				{Name: "002", AppliedAt: appliedAt},
+			}},
+			migrations: migrations,
+			want: StatusReport{
+				Current: "002",
+				Migrations: []MigrationStatus{
+					{Name: "001", State: MigrationApplied, AppliedAt: appliedAt},
+					{Name: "002", State: MigrationApplied, AppliedAt: appliedAt},
+					{Name: "003", State: MigrationPending},
+					{Name: "000_removed", State: MigrationMissing, AppliedAt: appliedAt},
+				},
+			},
+		},
+		{
+			name: "dirty history row without a migration",
+			indicator: &memoryIndicator{current: "001", history: []AppliedMigration{
+				{Name: "001", AppliedAt: appliedAt},
+				{Name: "001_removed", AppliedAt: appliedAt, Dirty: true},
+			}},
+			migrations: migrations,
+			want: StatusReport{
+				Current: "001",
+				Dirty:   true,
+				Migrations: []MigrationStatus{
+					{Name: "001", State: MigrationApplied, AppliedAt: appliedAt},
+					{Name: "002", State: MigrationPending},
+					{Name: "003", State: MigrationPending},
+					{Name: "001_removed", State: MigrationMissing, AppliedAt: appliedAt, Dirty: true},
+				},
+			},
+		},
+		{
+			name:       "current migration without a migration",
+			indicator:  &memoryIndicator{current: "004"},
+			migrations: migrations,
+			want: StatusReport{
+				Current: "004",
+				Migrations: []MigrationStatus{
+					{Name: "001", State: MigrationPending},
+					{Name: "002", State: MigrationPending},
+					{Name: "003", State: MigrationPending},
+					{Name: "004", State: MigrationMissing},
+				},
+			},
+		},
+		{
+			name:      "squashed migration replacing the current one",
+			indicator: &memoryIndicator{current: "002"},
+			migrations: []Migration{
+				Squashed{MigrationName: "002_squashed", ReplacedNames: []string{"001", "002"}},
+				newTestMigration("003", nil),
+			},
+			want: StatusReport{
+				Current: "002",
+				Migrations: []MigrationStatus{
+					{Name: "002_squashed", State: MigrationApplied},
+					{Name: "003", State: MigrationPending},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := NewRunner(test.indicator, NewBuilderPostgreSQL()).Status(test.migrations...)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Status() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStatusReportFilters(t *testing.T) {
+	report := StatusReport{Migrations: []MigrationStatus{
+		{Name: "001", State: MigrationApplied},
+		{Name: "002", State: MigrationPending},
+		{Name: "000", State: MigrationMissing},
+	}}
+
+	if applied := report.Applied(); len(applied) != 1 || applied[0].Name != "001" {
+		t.Errorf("Applied() = %+v", applied)
+	}
+	if pending := report.Pending(); len(pending) != 1 || pending[0].Name != "002" {
+		t.Errorf("Pending() = %+v", pending)
+	}
+	if missing := report.Missing(); len(missing) != 1 || missing[0].Name != "000" {
+		t.Errorf("Missing() = %+v", missing)
+	}
+}
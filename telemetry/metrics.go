@@ -0,0 +1,97 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/ItsMalma/gomimi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// MetricsObserver counts and times migrations and statements,
+// its metrics are registered on the registerer it was created with
+type MetricsObserver struct {
+	gomimi.NopObserver
+	migrationsApplied *prometheus.CounterVec
+	migrationFailures *prometheus.CounterVec
+	migrationDuration *prometheus.HistogramVec
+	statementDuration *prometheus.HistogramVec
+	rollbacks         *prometheus.CounterVec
+	currentMigration  *prometheus.GaugeVec
+	lastRunSuccess    prometheus.Gauge
+}
+
+func NewMetricsObserver(registerer prometheus.Registerer, dialect string) MetricsObserver {
+	factory := promauto.With(registerer)
+	constLabels := prometheus.Labels{"dialect": dialect}
+
+	return MetricsObserver{
+		migrationsApplied: factory.NewCounterVec(prometheus.CounterOpts{
+			Name:        "gomimi_migrations_applied_total",
+			Help:        "Number of migrations applied successfully.",
+			ConstLabels: constLabels,
+		}, []string{"direction"}),
+		migrationFailures: factory.NewCounterVec(prometheus.CounterOpts{
+			Name:        "gomimi_migration_failures_total",
+			Help:        "Number of migrations that failed.",
+			ConstLabels: constLabels,
+		}, []string{"direction"}),
+		migrationDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "gomimi_migration_duration_seconds",
+			Help:        "Duration of migrations.",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(0.01, 4, 10),
+		}, []string{"direction"}),
+		statementDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "gomimi_statement_duration_seconds",
+			Help:        "Duration of the statements executed by migrations.",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, []string{"operation"}),
+		rollbacks: factory.NewCounterVec(prometheus.CounterOpts{
+			Name:        "gomimi_rollbacks_total",
+			Help:        "Number of migration transactions rolled back.",
+			ConstLabels: constLabels,
+		}, []string{"direction"}),
+		currentMigration: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "gomimi_current_migration",
+			Help:        "Current migration of the database as of the last run, always 1.",
+			ConstLabels: constLabels,
+		}, []string{"migration"}),
+		lastRunSuccess: factory.NewGauge(prometheus.GaugeOpts{
+			Name:        "gomimi_last_run_success",
+			Help:        "Whether the last migration run succeeded.",
+			ConstLabels: constLabels,
+		}),
+	}
+}
+
+func (observer MetricsObserver) AfterStatement(ctx context.Context, name string, direction gomimi.Direction, statement gomimi.Statement, duration time.Duration, err error) {
+	observer.statementDuration.WithLabelValues(statementOperation(statement.Query)).Observe(duration.Seconds())
+}
+
+func (observer MetricsObserver) AfterMigration(ctx context.Context, name string, direction gomimi.Direction, duration time.Duration, err error) {
+	observer.migrationDuration.WithLabelValues(direction.String()).Observe(duration.Seconds())
+	if err != nil {
+		observer.migrationFailures.WithLabelValues(direction.String()).Inc()
+		return
+	}
+	observer.migrationsApplied.WithLabelValues(direction.String()).Inc()
+}
+
+func (observer MetricsObserver) AfterRun(ctx context.Context, report gomimi.RunReport) {
+	observer.currentMigration.Reset()
+	if report.Current != "" {
+		observer.currentMigration.WithLabelValues(report.Current).Set(1)
+	}
+	if report.Err != nil {
+		observer.lastRunSuccess.Set(0)
+	} else {
+		observer.lastRunSuccess.Set(1)
+	}
+}
+
+func (observer MetricsObserver) OnRollback(ctx context.Context, name string, direction gomimi.Direction, err error) {
+	observer.rollbacks.WithLabelValues(direction.String()).Inc()
+}
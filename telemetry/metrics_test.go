@@ -0,0 +1,112 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ItsMalma/gomimi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsObserver(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	observer := NewMetricsObserver(registry, "postgresql")
+	ctx := context.Background()
+	errMigration := errors.New("migration failed")
+
+	statement := gomimi.Statement{Query: `CREATE TABLE "users" ("id" BIGINT);`}
+	observer.AfterStatement(ctx, "001", gomimi.DirectionUp, statement, 2*time.Millisecond, nil)
+	observer.AfterMigration(ctx, "001", gomimi.DirectionUp, 20*time.Millisecond, nil)
+	observer.AfterMigration(ctx, "002", gomimi.DirectionUp, 30*time.Millisecond, nil)
+	observer.AfterMigration(ctx, "003", gomimi.DirectionUp, 40*time.Millisecond, errMigration)
+	observer.OnRollback(ctx, "003", gomimi.DirectionUp, errMigration)
+	observer.AfterMigration(ctx, "003", gomimi.DirectionDown, 10*time.Millisecond, nil)
+	observer.AfterRun(ctx, gomimi.RunReport{Current: "002", Applied: []string{"001", "002"}, RolledBack: []string{"003"}, Err: errMigration})
+
+	counters := []struct {
+		name      string
+		collector prometheus.Collector
+		want      float64
+	}{
+		{"applied up", observer.migrationsApplied.WithLabelValues("up"), 2},
+		{"applied down", observer.migrationsApplied.WithLabelValues("down"), 1},
+		{"failures up", observer.migrationFailures.WithLabelValues("up"), 1},
+		{"rollbacks up", observer.rollbacks.WithLabelValues("up"), 1},
+		{"current migration", observer.currentMigration.WithLabelValues("002"), 1},
+		{"last run success", observer.lastRunSuccess, 0},
+	}
+	for _, counter := range counters {
+		if got := testutil.ToFloat64(counter.collector); got != counter.want {
+			t.Errorf("%v = %v, want %v", counter.name, got, counter.want)
+		}
+	}
+
+	expected := `
+# HELP gomimi_migration_duration_seconds Duration of migrations.
+# TYPE gomimi_migration_duration_seconds histogram
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="down",le="0.01"} 1
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="down",le="0.04"} 1
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="down",le="0.16"} 1
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="down",le="0.64"} 1
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="down",le="2.56"} 1
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="down",le="10.24"} 1
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="down",le="40.96"} 1
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="down",le="163.84"} 1
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="down",le="655.36"} 1
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="down",le="2621.44"} 1
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="down",le="+Inf"} 1
+gomimi_migration_duration_seconds_sum{dialect="postgresql",direction="down"} 0.01
+gomimi_migration_duration_seconds_count{dialect="postgresql",direction="down"} 1
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="up",le="0.01"} 0
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="up",le="0.04"} 3
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="up",le="0.16"} 3
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="up",le="0.64"} 3
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="up",le="2.56"} 3
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="up",le="10.24"} 3
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="up",le="40.96"} 3
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="up",le="163.84"} 3
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="up",le="655.36"} 3
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="up",le="2621.44"} 3
+gomimi_migration_duration_seconds_bucket{dialect="postgresql",direction="up",le="+Inf"} 3
+gomimi_migration_duration_seconds_sum{dialect="postgresql",direction="up"} 0.09
+gomimi_migration_duration_seconds_count{dialect="postgresql",direction="up"} 3
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "gomimi_migration_duration_seconds"); err != nil {
+		t.Error(err)
+	}
+
+	if count := testutil.CollectAndCount(observer.statementDuration, "gomimi_statement_duration_seconds"); count != 1 {
+		t.Errorf("statement duration series = %d, want 1", count)
+	}
+}
+
+func TestMetricsObserverCurrentMigration(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	observer := NewMetricsObserver(registry, "postgresql")
+	ctx := context.Background()
+
+	observer.AfterRun(ctx, gomimi.RunReport{Current: "001", Applied: []string{"001"}})
+	// a run with nothing pending still reports the current migration of the database
+	observer.AfterRun(ctx, gomimi.RunReport{Current: "001"})
+	if count := testutil.CollectAndCount(observer.currentMigration); count != 1 {
+		t.Fatalf("current migration series = %d, want 1", count)
+	}
+	if got := testutil.ToFloat64(observer.currentMigration.WithLabelValues("001")); got != 1 {
+		t.Errorf("current migration 001 = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(observer.lastRunSuccess); got != 1 {
+		t.Errorf("last run success = %v, want 1", got)
+	}
+
+	observer.AfterRun(ctx, gomimi.RunReport{Current: "002"})
+	if count := testutil.CollectAndCount(observer.currentMigration); count != 1 {
+		t.Errorf("current migration series = %d, want 1", count)
+	}
+	if got := testutil.ToFloat64(observer.currentMigration.WithLabelValues("002")); got != 1 {
+		t.Errorf("current migration 002 = %v, want 1", got)
+	}
+}
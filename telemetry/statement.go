@@ -0,0 +1,26 @@
+package telemetry
+
+import (
+	"regexp"
+	"strings"
+)
+
+// the builder always quotes identifiers,
+// so the first quoted name after one of these keywords is the table of the statement
+var statementTablePattern = regexp.MustCompile(`(?i)\b(?:TABLE(?:\s+IF\s+(?:NOT\s+)?EXISTS)?|ONLY|ON|INTO|UPDATE|FROM)\s+((?:"[^"]+"\.)?"[^"]+")`)
+
+func statementTable(query string) string {
+	match := statementTablePattern.FindStringSubmatch(query)
+	if match == nil {
+		return ""
+	}
+	return strings.ReplaceAll(match[1], `"`, "")
+}
+
+func statementOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(strings.TrimSuffix(fields[0], ";"))
+}
@@ -0,0 +1,93 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/ItsMalma/gomimi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/ItsMalma/gomimi/telemetry"
+
+// TracingObserver creates a span for every run, migration and statement,
+// nested in the span of the context the runner was given
+type TracingObserver struct {
+	gomimi.NopObserver
+	tracer  trace.Tracer
+	dialect string
+}
+
+func NewTracingObserver(tracerProvider trace.TracerProvider, dialect string) TracingObserver {
+	return TracingObserver{
+		tracer:  tracerProvider.Tracer(instrumentationName),
+		dialect: dialect,
+	}
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (observer TracingObserver) BeforeRun(ctx context.Context, migrations []gomimi.Migration) context.Context {
+	ctx, _ = observer.tracer.Start(ctx, "gomimi.run", trace.WithAttributes(
+		attribute.String("db.system", observer.dialect),
+		attribute.Int("gomimi.migrations", len(migrations)),
+	))
+	return ctx
+}
+
+func (observer TracingObserver) BeforeMigration(ctx context.Context, name string, direction gomimi.Direction) context.Context {
+	ctx, _ = observer.tracer.Start(ctx, "gomimi.migration "+name, trace.WithAttributes(
+		attribute.String("db.system", observer.dialect),
+		attribute.String("gomimi.migration", name),
+		attribute.String("gomimi.direction", direction.String()),
+	))
+	return ctx
+}
+
+func (observer TracingObserver) BeforeStatement(ctx context.Context, name string, direction gomimi.Direction, statement gomimi.Statement) context.Context {
+	attributes := []attribute.KeyValue{
+		attribute.String("db.system", observer.dialect),
+		attribute.String("db.statement", statement.Redacted()),
+		attribute.String("db.operation", statementOperation(statement.Query)),
+		attribute.String("gomimi.migration", name),
+		attribute.String("gomimi.direction", direction.String()),
+	}
+	if table := statementTable(statement.Query); table != "" {
+		attributes = append(attributes, attribute.String("db.sql.table", table))
+	}
+
+	ctx, _ = observer.tracer.Start(ctx, "gomimi.statement", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	return ctx
+}
+
+func (observer TracingObserver) AfterStatement(ctx context.Context, name string, direction gomimi.Direction, statement gomimi.Statement, duration time.Duration, err error) {
+	endSpan(trace.SpanFromContext(ctx), err)
+}
+
+func (observer TracingObserver) AfterMigration(ctx context.Context, name string, direction gomimi.Direction, duration time.Duration, err error) {
+	endSpan(trace.SpanFromContext(ctx), err)
+}
+
+func (observer TracingObserver) AfterRun(ctx context.Context, report gomimi.RunReport) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.Int("gomimi.applied", len(report.Applied)),
+		attribute.Int("gomimi.rolled_back", len(report.RolledBack)),
+	)
+	if report.Failed != "" {
+		span.SetAttributes(attribute.String("gomimi.failed", report.Failed))
+	}
+	endSpan(span, report.Err)
+}
+
+func (observer TracingObserver) OnRollback(ctx context.Context, name string, direction gomimi.Direction, err error) {
+	trace.SpanFromContext(ctx).AddEvent("rollback", trace.WithAttributes(attribute.String("error", err.Error())))
+}
@@ -0,0 +1,153 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ItsMalma/gomimi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, keyValue := range span.Attributes() {
+		attributes[keyValue.Key] = keyValue.Value
+	}
+	return attributes
+}
+
+func TestTracingObserver(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	observer := NewTracingObserver(tracerProvider, "postgresql")
+
+	statement := gomimi.Statement{Query: `CREATE TABLE "public"."users" ("id" BIGINT);`}
+	errStatement := errors.New("statement failed")
+
+	ctx := observer.BeforeRun(context.Background(), nil)
+	migrationCtx := observer.BeforeMigration(ctx, "001", gomimi.DirectionUp)
+	statementCtx := observer.BeforeStatement(migrationCtx, "001", gomimi.DirectionUp, statement)
+	observer.AfterStatement(statementCtx, "001", gomimi.DirectionUp, statement, time.Millisecond, errStatement)
+	observer.OnRollback(migrationCtx, "001", gomimi.DirectionUp, errStatement)
+	observer.AfterMigration(migrationCtx, "001", gomimi.DirectionUp, time.Millisecond, errStatement)
+	observer.AfterRun(ctx, gomimi.RunReport{RolledBack: []string{"001"}, Err: errStatement})
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("ended spans = %d, want 3", len(spans))
+	}
+	statementSpan, migrationSpan, runSpan := spans[0], spans[1], spans[2]
+
+	tests := []struct {
+		span       sdktrace.ReadOnlySpan
+		name       string
+		parent     sdktrace.ReadOnlySpan
+		attributes map[attribute.Key]attribute.Value
+	}{
+		{
+			span:   runSpan,
+			name:   "gomimi.run",
+			parent: nil,
+			attributes: map[attribute.Key]attribute.Value{
+				"db.system":          attribute.StringValue("postgresql"),
+				"gomimi.applied":     attribute.IntValue(0),
+				"gomimi.rolled_back": attribute.IntValue(1),
+			},
+		},
+		{
+			span:   migrationSpan,
+			name:   "gomimi.migration 001",
+			parent: runSpan,
+			attributes: map[attribute.Key]attribute.Value{
+				"gomimi.migration": attribute.StringValue("001"),
+				"gomimi.direction": attribute.StringValue("up"),
+			},
+		},
+		{
+			span:   statementSpan,
+			name:   "gomimi.statement",
+			parent: migrationSpan,
+			attributes: map[attribute.Key]attribute.Value{
+				"db.statement": attribute.StringValue(statement.Query),
+				"db.operation": attribute.StringValue("CREATE"),
+				"db.sql.table": attribute.StringValue("public.users"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.span.Name() != test.name {
+				t.Errorf("Name() = %q, want %q", test.span.Name(), test.name)
+			}
+			if test.parent == nil {
+				if test.span.Parent().IsValid() {
+					t.Errorf("Parent() = %v, want none", test.span.Parent().SpanID())
+				}
+			} else if test.span.Parent().SpanID() != test.parent.SpanContext().SpanID() {
+				t.Errorf("Parent() = %v, want %v", test.span.Parent().SpanID(), test.parent.SpanContext().SpanID())
+			}
+
+			attributes := spanAttributes(test.span)
+			for key, value := range test.attributes {
+				if attributes[key] != value {
+					t.Errorf("attribute %v = %v, want %v", key, attributes[key].Emit(), value.Emit())
+				}
+			}
+
+			if status := test.span.Status(); status.Code != codes.Error || status.Description != errStatement.Error() {
+				t.Errorf("Status() = %+v, want error %q", status, errStatement)
+			}
+		})
+	}
+
+	// the rollback is recorded before the error that caused it
+	events := migrationSpan.Events()
+	if len(events) != 2 || events[0].Name != "rollback" || events[1].Name != "exception" {
+		t.Errorf("migration span events = %+v, want a rollback and an exception event", events)
+	}
+}
+
+func TestTracingObserverSuccess(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	observer := NewTracingObserver(tracerProvider, "postgresql")
+
+	ctx := observer.BeforeRun(context.Background(), nil)
+	migrationCtx := observer.BeforeMigration(ctx, "001", gomimi.DirectionUp)
+	observer.AfterMigration(migrationCtx, "001", gomimi.DirectionUp, time.Millisecond, nil)
+	observer.AfterRun(ctx, gomimi.RunReport{Applied: []string{"001"}})
+
+	for _, span := range recorder.Ended() {
+		if span.Status().Code != codes.Unset {
+			t.Errorf("%v Status() = %+v, want unset", span.Name(), span.Status())
+		}
+		if _, ok := spanAttributes(span)["gomimi.failed"]; ok {
+			t.Errorf("%v has the gomimi.failed attribute", span.Name())
+		}
+	}
+}
+
+func TestTracingObserverRedactsPasswords(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	observer := NewTracingObserver(tracerProvider, "postgresql")
+
+	statement := gomimi.Statement{Query: `ALTER ROLE "app" WITH PASSWORD 'secret';`}
+	ctx := observer.BeforeStatement(context.Background(), "001", gomimi.DirectionUp, statement)
+	observer.AfterStatement(ctx, "001", gomimi.DirectionUp, statement, time.Millisecond, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(spans))
+	}
+	want := `ALTER ROLE "app" WITH PASSWORD '[REDACTED]';`
+	if got := spanAttributes(spans[0])["db.statement"].AsString(); got != want {
+		t.Errorf("db.statement = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package gomimi
+
+import (
+	"context"
+	"time"
+)
+
+// TimeoutMigration can be implemented by a migration
+// to override the timeouts the runner was given
+type TimeoutMigration interface {
+	Timeouts() Timeouts
+}
+
+func (timeouts Timeouts) override(other Timeouts) Timeouts {
+	if other.LockTimeout > 0 {
+		timeouts.LockTimeout = other.LockTimeout
+	}
+	if other.StatementTimeout > 0 {
+		timeouts.StatementTimeout = other.StatementTimeout
+	}
+	return timeouts
+}
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Retryable      func(err error) bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Retryable:      IsLockTimeoutErrorPostgreSQL,
+	}
+}
+
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := policy.InitialBackoff
+	for ; attempt > 1; attempt-- {
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff >= policy.MaxBackoff {
+			return policy.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+func (policy RetryPolicy) retry(ctx context.Context, run func() error, onRetry func(attempt int, backoff time.Duration, err error)) error {
+	for attempt := 1; ; attempt++ {
+		err := run()
+		if err == nil || policy.Retryable == nil || !policy.Retryable(err) || attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		backoff := policy.backoff(attempt)
+		onRetry(attempt, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
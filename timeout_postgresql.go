@@ -0,0 +1,14 @@
+package gomimi
+
+import "errors"
+
+// both lib/pq and pgx expose the SQLSTATE code of an error this way
+type sqlStateError interface {
+	SQLState() string
+}
+
+func IsLockTimeoutErrorPostgreSQL(err error) bool {
+	var stateErr sqlStateError
+	// 55P03 is lock_not_available, raised when lock_timeout is exceeded
+	return errors.As(err, &stateErr) && stateErr.SQLState() == "55P03"
+}
@@ -0,0 +1,111 @@
+package types
+
+type Kind uint8
+
+const (
+	KindRaw Kind = iota
+	KindSmallInt
+	KindInteger
+	KindBigInt
+	KindReal
+	KindDouble
+	KindDecimal
+	KindBoolean
+	KindChar
+	KindVarchar
+	KindText
+	KindBinary
+	KindDate
+	KindTime
+	KindTimestamp
+	KindInterval
+	KindJSON
+	KindUUID
+	KindArray
+)
+
+type Type struct {
+	Kind         Kind
+	Name         string
+	Length       int
+	Precision    int
+	Scale        int
+	WithTimeZone bool
+	Element      *Type
+}
+
+func Raw(name string) Type {
+	return Type{Kind: KindRaw, Name: name}
+}
+
+func SmallInt() Type {
+	return Type{Kind: KindSmallInt}
+}
+
+func Integer() Type {
+	return Type{Kind: KindInteger}
+}
+
+func BigInt() Type {
+	return Type{Kind: KindBigInt}
+}
+
+func Real() Type {
+	return Type{Kind: KindReal}
+}
+
+func Double() Type {
+	return Type{Kind: KindDouble}
+}
+
+func Decimal(precision int, scale int) Type {
+	return Type{Kind: KindDecimal, Precision: precision, Scale: scale}
+}
+
+func Boolean() Type {
+	return Type{Kind: KindBoolean}
+}
+
+func Char(length int) Type {
+	return Type{Kind: KindChar, Length: length}
+}
+
+func Varchar(length int) Type {
+	return Type{Kind: KindVarchar, Length: length}
+}
+
+func Text() Type {
+	return Type{Kind: KindText}
+}
+
+func Binary() Type {
+	return Type{Kind: KindBinary}
+}
+
+func Date() Type {
+	return Type{Kind: KindDate}
+}
+
+func Time(withTimeZone bool) Type {
+	return Type{Kind: KindTime, WithTimeZone: withTimeZone}
+}
+
+func Timestamp(withTimeZone bool) Type {
+	return Type{Kind: KindTimestamp, WithTimeZone: withTimeZone}
+}
+
+func Interval() Type {
+	return Type{Kind: KindInterval}
+}
+
+func JSON() Type {
+	return Type{Kind: KindJSON}
+}
+
+func UUID() Type {
+	return Type{Kind: KindUUID}
+}
+
+func Array(element Type) Type {
+	return Type{Kind: KindArray, Element: &element}
+}